@@ -28,7 +28,7 @@ func NewCookieWriter(w http.ResponseWriter) *CookieWriter {
 func (self *CookieWriter) Set(key, value string, age int) {
 	http.SetCookie(self.w, &http.Cookie{
 		Name:     key,
-		Value:    self.hash(value) + "+" + value,
+		Value:    SignCookie(value),
 		Path:     "/",
 		MaxAge:   age,
 		HttpOnly: true,
@@ -49,14 +49,6 @@ func (self *CookieWriter) Del(key string) {
 	})
 }
 
-// Создание хеша, который будет приписан к значению куки
-// Целью хеша является ключ для проверки приходящих кук на предмет их модификации клиентом
-func (self *CookieWriter) hash(value string) string {
-	h := hmac.New(sha256.New, []byte(TOKEN))
-	h.Write([]byte(value))
-	return fmt.Sprintf("%x", h.Sum(nil))
-}
-
 // CookieReader принимает http.Request, и служит для чтения кук пришедших от клиента
 type CookieReader struct {
 	data map[string]*http.Cookie
@@ -75,7 +67,8 @@ func NewCookieReader(r *http.Request) *CookieReader {
 // Получения значения куки, установленной через ctx.Res.Cookies.Set
 func (self *CookieReader) Get(key string) string {
 	if self.Exists(key) {
-		return self.parse(self.data[key].Value)
+		value, _ := VerifyCookie(self.data[key].Value)
+		return value
 	}
 	return ""
 }
@@ -92,26 +85,33 @@ func (self *CookieReader) GetRaw(key string) *http.Cookie {
 	return self.data[key]
 }
 
-// Создание хеша, для проверки куки на предмет модификации
-func (self *CookieReader) hash(value string) string {
-	h := hmac.New(sha256.New, []byte(TOKEN))
-	h.Write([]byte(value))
-	return fmt.Sprintf("%x", h.Sum(nil))
+// Подписывает значение куки хешем в том виде, в котором его устанавливает CookieWriter.Set
+func SignCookie(value string) string {
+	return cookieHash(value) + "+" + value
 }
 
-// Валидации куки с проверкой хеша
-func (self *CookieReader) parse(value string) string {
-	if len(value) == 0 {
-		return ""
+// Проверяет подпись значения куки и возвращает исходное значение
+// Если подпись не совпадает, возвращается пустая строка и false
+func VerifyCookie(signed string) (string, bool) {
+	if len(signed) == 0 {
+		return "", false
 	}
-	segments := strings.Split(value, "+")
+	segments := strings.Split(signed, "+")
 	if len(segments) == 1 {
-		return ""
+		return "", false
 	}
 	hash := segments[0]
-	value = strings.Join(segments[1:], "+")
-	if hash == self.hash(value) {
-		return value
+	value := strings.Join(segments[1:], "+")
+	if hmac.Equal([]byte(hash), []byte(cookieHash(value))) {
+		return value, true
 	}
-	return ""
+	return "", false
+}
+
+// Создание хеша, который будет приписан к значению куки
+// Целью хеша является ключ для проверки приходящих кук на предмет их модификации клиентом
+func cookieHash(value string) string {
+	h := hmac.New(sha256.New, []byte(TOKEN))
+	h.Write([]byte(value))
+	return fmt.Sprintf("%x", h.Sum(nil))
 }
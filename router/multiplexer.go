@@ -2,13 +2,32 @@ package router
 
 import (
 	"io"
+	"net"
 	"net/http"
 	"path"
 )
 
+// Политика обработки завершающего слеша в пути запроса
+type SlashPolicy int
+
+const (
+	// Добавить слеш и перенаправить, если маршрут найден только со слешем (по умолчанию)
+	SlashRedirectAdd SlashPolicy = iota
+	// Путь должен совпадать с маршрутом в точности
+	SlashStrict
+	// Убрать слеш и перенаправить, если маршрут найден только без слеша
+	SlashRedirectRemove
+	// Обслужить запрос без перенаправления, если маршрут найден со слешем или без него
+	SlashMatchBoth
+)
+
 type Multiplexer struct {
-	routes Routes
-	logger Logger
+	routes     Routes
+	logger     Logger
+	slash      SlashPolicy
+	cleanPath  bool
+	ignoreCase bool
+	renderer   Renderer
 }
 
 func NewMultiplexer(w io.Writer) *Multiplexer {
@@ -20,44 +39,150 @@ func NewMultiplexer(w io.Writer) *Multiplexer {
 
 func (self *Multiplexer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := NewCtx(w, r)
+	ctx.Res.Renderer = self.renderer
+
+	if self.cleanPath {
+		urlpath := ctx.Req.URL.Path
+		if cleaned := cleanPath(urlpath); cleaned != urlpath {
+			self.redirect(ctx, cleaned)
+			return
+		}
+	}
+
 	route, err := self.routing(ctx.Req)
 	if err != nil {
-		urlpath := ctx.Req.URL.Path
-		if urlpath[len(urlpath)-1] != '/' {
-			ext := path.Ext(urlpath)
-			if len(ext) == 0 {
-				ctx.Req.URL.Path += "/"
-				_, err := self.routing(ctx.Req)
-				if err == nil {
-					ctx.Res.Status(http.StatusFound)
-					ctx.Res.Redirect(ctx.Req.URL.String(), http.StatusMovedPermanently)
-					return
-				}
-			}
+		route = self.slashFallback(ctx)
+		if route == nil {
+			ctx.Res.Status(http.StatusNotFound)
+			return
 		}
-		ctx.Res.Status(http.StatusNotFound)
-		return
 	}
 
+	defer ctx.Req.cleanup()
+
 	if err := route.FnChain(ctx); err != nil {
 		self.logger.Println(err)
 	}
 }
 
+// Поиск маршрута по пути с добавленным или убранным завершающим слешем
+// При перенаправлении возвращает nil, ответ к этому моменту уже записан
+func (self *Multiplexer) slashFallback(ctx *Ctx) *Route {
+	urlpath := ctx.Req.URL.Path
+	if self.slash == SlashStrict || urlpath == "" || urlpath == "/" {
+		return nil
+	}
+
+	var alternate string
+	switch {
+	case urlpath[len(urlpath)-1] == '/':
+		if self.slash != SlashRedirectRemove && self.slash != SlashMatchBoth {
+			return nil
+		}
+		alternate = urlpath[:len(urlpath)-1]
+	case len(path.Ext(urlpath)) == 0:
+		if self.slash != SlashRedirectAdd && self.slash != SlashMatchBoth {
+			return nil
+		}
+		alternate = urlpath + "/"
+	default:
+		return nil
+	}
+
+	ctx.Req.URL.Path = alternate
+	route, err := self.routing(ctx.Req)
+	ctx.Req.URL.Path = urlpath
+	if err != nil {
+		return nil
+	}
+
+	if self.slash == SlashMatchBoth {
+		return route
+	}
+
+	self.redirect(ctx, alternate)
+	return nil
+}
+
+// Перенаправление на исправленный путь с сохранением строки запроса
+// Для запросов с телом используется 308, чтобы клиент повторил метод и тело
+func (self *Multiplexer) redirect(ctx *Ctx, urlpath string) {
+	target := urlpath
+	if ctx.Req.URL.RawQuery != "" {
+		target += "?" + ctx.Req.URL.RawQuery
+	}
+	code := http.StatusMovedPermanently
+	if ctx.Req.Method != GET && ctx.Req.Method != HEAD {
+		code = http.StatusPermanentRedirect
+	}
+	ctx.Res.Redirect(target, code)
+}
+
+// Очистка пути от повторяющихся слешей и сегментов . и ..
+// Завершающий слеш сохраняется
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := path.Clean(p)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// Сначала проверяются маршруты привязанные к хосту, затем маршруты
+// с условиями на схему и заголовки, и только потом маршруты без условий,
+// которые обслуживают все остальные запросы, независимо от порядка регистрации
 func (self *Multiplexer) routing(req *Request) (*Route, error) {
-	for _, route := range self.routes[req.Method] {
-		if self.checkRoute(req, route) {
-			return route, nil
+	for priority := 0; priority < 3; priority++ {
+		for _, route := range self.routes[req.Method] {
+			if routePriority(route) == priority && self.checkRoute(req, route) {
+				return route, nil
+			}
 		}
 	}
 	return nil, ErrRouteNotFound
 }
 
+func routePriority(r *Route) int {
+	switch {
+	case r.Host != nil:
+		return 0
+	case len(r.Matchers) > 0:
+		return 1
+	}
+	return 2
+}
+
 func (self *Multiplexer) checkRoute(req *Request, r *Route) bool {
+	params := make(URLParams)
+
+	if r.Host != nil {
+		host := requestHost(req.Request)
+		matches := r.Host.FindStringSubmatch(host)
+		if len(matches) == 0 {
+			return false
+		}
+		for i, name := range r.Host.SubexpNames() {
+			if len(name) > 0 {
+				params[name] = matches[i]
+			}
+		}
+	}
+
+	for _, match := range r.Matchers {
+		if !match(req) {
+			return false
+		}
+	}
+
 	path := req.URL.Path
 	matches := r.RegExp.FindStringSubmatch(path)
 	if len(matches) > 0 && matches[0] == path {
-		params := make(URLParams)
 		for i, name := range r.RegExp.SubexpNames() {
 			if len(name) > 0 {
 				params[name] = matches[i]
@@ -68,3 +193,12 @@ func (self *Multiplexer) checkRoute(req *Request, r *Route) bool {
 	}
 	return false
 }
+
+// Хост запроса без порта
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
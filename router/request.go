@@ -8,15 +8,16 @@ import (
 // Обертка над http.Request
 type Request struct {
 	*http.Request
-	Cookies *CookieReader
-	Params  URLParams
+	Cookies  *CookieReader
+	Params   URLParams
+	cleanups []func()
 }
 
 func NewRequest(r *http.Request) *Request {
 	c := NewCookieReader(r)
 	p := NewURLParams()
 	return &Request{
-		r, c, p,
+		r, c, p, nil,
 	}
 }
 
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"net/http"
@@ -8,15 +9,16 @@ import (
 
 // Обертка над http.ResponseWriter
 type Response struct {
-	Writer  http.ResponseWriter
-	Request *http.Request
-	Cookies *CookieWriter
+	Writer   http.ResponseWriter
+	Request  *http.Request
+	Cookies  *CookieWriter
+	Renderer Renderer
 }
 
 func NewResponse(w http.ResponseWriter, r *http.Request) *Response {
 	c := NewCookieWriter(w)
 	return &Response{
-		w, r, c,
+		w, r, c, nil,
 	}
 }
 
@@ -46,6 +48,23 @@ func (self *Response) Xml(obj interface{}) error {
 	return self.Raw(res)
 }
 
+// Рендеринг шаблона с помощью Renderer, установленного через Router.SetRenderer
+// Шаблон сначала рендерится в буфер, поэтому при ошибке в ответ ничего не пишется
+func (self *Response) Render(status int, name string, data interface{}) error {
+	if self.Renderer == nil {
+		return ErrRendererNotDefined
+	}
+	buf := &bytes.Buffer{}
+	if err := self.Renderer.Render(buf, self.Request, name, data); err != nil {
+		return err
+	}
+	if self.Writer.Header().Get("Content-Type") == "" {
+		self.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	self.Writer.WriteHeader(status)
+	return self.Raw(buf.Bytes())
+}
+
 func (self *Response) Raw(data []byte) error {
 	_, err := self.Writer.Write(data)
 	return err
@@ -2,9 +2,12 @@ package router
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strings"
 )
 
 const (
@@ -16,10 +19,12 @@ const (
 )
 
 var (
-	ErrRouteNotFound = errors.New("Route not found")
+	ErrRouteNotFound      = errors.New("Route not found")
+	ErrRendererNotDefined = errors.New("Renderer not defined")
 )
 
 var regexpPlaceholder = regexp.MustCompile(`:([\w]+)`)
+var regexpHostPlaceholder = regexp.MustCompile(`\\\{([\w]+)\\\}`)
 
 type Handler func(*Ctx) error
 
@@ -34,28 +39,67 @@ func WrapHandler(h http.Handler) Handler {
 	}
 }
 
+// Дополнительное условие выбора маршрута помимо метода и пути
+type Matcher func(*Request) bool
+
 type Route struct {
 	*Interceptor
-	Method  string
-	Pattern string
-	Handler Handler
-	FnChain func(ctx *Ctx) error
-	RegExp  *regexp.Regexp
+	Method   string
+	Path     string
+	Pattern  string
+	Handler  Handler
+	FnChain  func(ctx *Ctx) error
+	RegExp   *regexp.Regexp
+	Host     *regexp.Regexp
+	Matchers []Matcher
+	name     string
 }
 
 func NewRoute(method string, pattern string, handler Handler) *Route {
+	path := pattern
 	pattern = regexp.QuoteMeta(pattern)
 	pattern = regexpPlaceholder.ReplaceAllString(pattern, `(?P<$1>[0-9A-Za-z\-]+)`)
 	rexp := regexp.MustCompile(pattern)
 	return &Route{
 		Interceptor: NewInterceptor(),
 		Method:      method,
+		Path:        path,
 		Pattern:     pattern,
 		Handler:     handler,
 		RegExp:      rexp,
 	}
 }
 
+// Имя маршрута для построения адреса через Router.URL
+func (self *Route) Name(name string) *Route {
+	self.name = name
+	return self
+}
+
+// Адрес маршрута с подставленными параметрами
+// Параметры передаются парами: "id", "5", "slug", "news"
+func (self *Route) URL(params ...string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("router: odd number of parameters for route %q", self.name)
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+	var err error
+	result := regexpPlaceholder.ReplaceAllStringFunc(self.Path, func(s string) string {
+		value, ok := values[s[1:]]
+		if !ok && err == nil {
+			err = fmt.Errorf("router: missing parameter %q for route %q", s[1:], self.name)
+		}
+		return url.PathEscape(value)
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
 type Routes map[string][]*Route
 
 func NewRoutes() Routes {
@@ -89,10 +133,63 @@ func (self *Router) Group(prefix string) *Grouper {
 	return g
 }
 
+// Группа маршрутов для определенного хоста
+// Шаблон может содержать параметры, которые будут доступны в URLParams:
+// r.Host("api.{region}.example.com")
+// Маршруты без хоста обслуживают все остальные хосты
+func (self *Router) Host(pattern string) *Grouper {
+	g := NewGrouper("")
+	g.host = NewHost(pattern)
+	self.groups = append(self.groups, g)
+	return g
+}
+
+// Политика обработки завершающего слеша, по умолчанию SlashRedirectAdd
+func (self *Router) TrailingSlash(policy SlashPolicy) *Router {
+	self.Mux.slash = policy
+	return self
+}
+
+// Перенаправлять пути с // и сегментами . и .. на очищенный путь
+func (self *Router) CleanPath(enable bool) *Router {
+	self.Mux.cleanPath = enable
+	return self
+}
+
+// Сопоставлять путь с маршрутами без учета регистра
+func (self *Router) IgnoreCase(enable bool) *Router {
+	self.Mux.ignoreCase = enable
+	return self
+}
+
+// Установка шаблонизатора для ctx.Res.Render
+func (self *Router) SetRenderer(renderer Renderer) *Router {
+	self.Mux.renderer = renderer
+	return self
+}
+
+// Адрес именованного маршрута, удобно использовать в шаблонах:
+// view.Funcs(template.FuncMap{"url": r.URL})
+func (self *Router) URL(name string, params ...string) (string, error) {
+	groupers := append([]*Grouper{self.Grouper}, self.groups...)
+	for _, g := range groupers {
+		for _, routes := range g.Routes {
+			for _, route := range routes {
+				if route.name == name {
+					return route.URL(params...)
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("router: route %q not found", name)
+}
+
 func (self *Router) Handler() http.Handler {
 	for method, routes := range self.Routes {
 		for _, route := range routes {
 
+			self.Grouper.bound(route)
+			self.compile(route)
 			route.FnChain = compose(merge(
 				self.middlewares,
 				route.middlewares,
@@ -107,6 +204,8 @@ func (self *Router) Handler() http.Handler {
 		for method, routes := range group.Routes {
 			for _, route := range routes {
 
+				group.bound(route)
+				self.compile(route)
 				route.FnChain = compose(merge(
 					self.middlewares,
 					group.middlewares,
@@ -122,10 +221,19 @@ func (self *Router) Handler() http.Handler {
 	return self.Mux
 }
 
+// Перекомпилирует маршрут с учетом настроек сопоставления
+func (self *Router) compile(route *Route) {
+	if self.Mux.ignoreCase {
+		route.RegExp = regexp.MustCompile(`(?i)` + route.Pattern)
+	}
+}
+
 type Grouper struct {
 	*Interceptor
-	Routes Routes
-	prefix string
+	Routes   Routes
+	prefix   string
+	host     *regexp.Regexp
+	matchers []Matcher
 }
 
 func NewGrouper(prefix string) *Grouper {
@@ -136,6 +244,42 @@ func NewGrouper(prefix string) *Grouper {
 	}
 }
 
+// Ограничивает группу схемами запроса: http, https
+func (self *Grouper) Scheme(schemes ...string) *Grouper {
+	return self.Match(func(req *Request) bool {
+		scheme := RequestScheme(req.Request)
+		for _, s := range schemes {
+			if strings.EqualFold(s, scheme) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Ограничивает группу запросами с заголовком key равным value
+// Пустой value проверяет только наличие заголовка
+func (self *Grouper) Header(key, value string) *Grouper {
+	return self.Match(func(req *Request) bool {
+		if value == "" {
+			return len(req.Header.Values(key)) > 0
+		}
+		return req.Header.Get(key) == value
+	})
+}
+
+// Добавляет произвольное условие выбора маршрутов группы
+func (self *Grouper) Match(fn Matcher) *Grouper {
+	self.matchers = append(self.matchers, fn)
+	return self
+}
+
+// Переносит условия группы на маршрут
+func (self *Grouper) bound(route *Route) {
+	route.Host = self.host
+	route.Matchers = append(self.matchers[:len(self.matchers):len(self.matchers)], route.Matchers...)
+}
+
 func (self *Grouper) Get(pattern string, fn Handler) *Route {
 	return self.registr(GET, pattern, fn)
 }
@@ -161,3 +305,26 @@ func (self *Grouper) registr(method string, pattern string, fn Handler) *Route {
 	self.Routes[method] = append(self.Routes[method], r)
 	return r
 }
+
+// Компилирует шаблон хоста в регулярное выражение
+// Параметры {name} совпадают с одним сегментом имени хоста
+// Хост сравнивается без учета регистра, имена параметров сохраняются как есть
+func NewHost(pattern string) *regexp.Regexp {
+	pattern = regexp.QuoteMeta(pattern)
+	pattern = regexpHostPlaceholder.ReplaceAllString(pattern, `(?P<$1>[^.]+)`)
+	return regexp.MustCompile(`^(?i)` + pattern + `$`)
+}
+
+// Схема запроса с учетом TLS и заголовка X-Forwarded-Proto от прокси
+func RequestScheme(r *http.Request) string {
+	if r.URL.Scheme != "" {
+		return r.URL.Scheme
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return strings.ToLower(proto)
+	}
+	return "http"
+}
@@ -324,3 +324,200 @@ func TestWrapHandler(t *testing.T) {
 		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), expected)
 	}
 }
+
+func TestHost(t *testing.T) {
+	r := New(nil)
+	r.Get("/users", func(ctx *Ctx) error {
+		return ctx.Res.Text("default")
+	})
+	r.Get("/status", func(ctx *Ctx) error {
+		return ctx.Res.Text("status")
+	})
+
+	api := r.Host("API.{regionID}.example.com")
+	{
+		api.Get("/users", func(ctx *Ctx) error {
+			return ctx.Res.Text("api " + ctx.Req.Params.Get("regionID"))
+		})
+		api.Get("/users/:id", func(ctx *Ctx) error {
+			return ctx.Res.Text(ctx.Req.Params.Get("regionID") + " " + ctx.Req.Params.Get("id"))
+		})
+	}
+
+	mux := r.Handler()
+
+	tests := []struct {
+		host   string
+		path   string
+		status int
+		body   string
+	}{
+		{"api.eu.example.com", "/users", http.StatusOK, "api eu"},
+		{"API.US.example.com:8080", "/users/5", http.StatusOK, "US 5"},
+		{"api.eu.example.com", "/status", http.StatusOK, "status"},
+		{"www.example.com", "/users", http.StatusOK, "default"},
+		{"www.example.com", "/users/5", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.path, nil)
+		req.Host = test.host
+		res := httptest.NewRecorder()
+
+		mux.ServeHTTP(res, req)
+
+		if status := res.Code; status != test.status {
+			t.Errorf("%s%s: handler returned wrong status code: got %v want %v", test.host, test.path, status, test.status)
+		}
+		if res.Body.String() != test.body {
+			t.Errorf("%s%s: handler returned unexpected body: got %v want %v", test.host, test.path, res.Body.String(), test.body)
+		}
+	}
+}
+
+func TestSchemeAndHeader(t *testing.T) {
+	r := New(nil)
+	r.Get("/", func(ctx *Ctx) error {
+		return ctx.Res.Text("plain")
+	})
+
+	secure := r.Host("admin.example.com").Scheme("https").Header("X-Admin", "")
+	{
+		secure.Get("/", func(ctx *Ctx) error {
+			return ctx.Res.Text("admin")
+		})
+	}
+
+	mux := r.Handler()
+
+	req := httptest.NewRequest("GET", "https://admin.example.com/", nil)
+	req.Header.Set("X-Admin", "1")
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if res.Body.String() != "admin" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "admin")
+	}
+
+	req = httptest.NewRequest("GET", "http://admin.example.com/", nil)
+	req.Header.Set("X-Admin", "1")
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if res.Body.String() != "plain" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "plain")
+	}
+
+	req = httptest.NewRequest("GET", "https://admin.example.com/", nil)
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if res.Body.String() != "plain" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "plain")
+	}
+}
+
+func TestMatcherPriority(t *testing.T) {
+	r := New(nil)
+	r.Get("/", func(ctx *Ctx) error {
+		return ctx.Res.Text("plain")
+	})
+
+	beta := r.Group("").Header("X-Beta", "1")
+	{
+		beta.Get("/", func(ctx *Ctx) error {
+			return ctx.Res.Text("beta")
+		})
+	}
+
+	mux := r.Handler()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Beta", "1")
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if res.Body.String() != "beta" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "beta")
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if res.Body.String() != "plain" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "plain")
+	}
+}
+
+func TestTrailingSlash(t *testing.T) {
+	handler := func(ctx *Ctx) error {
+		return ctx.Res.Text(ctx.Req.URL.Path)
+	}
+
+	tests := []struct {
+		policy   SlashPolicy
+		method   string
+		target   string
+		status   int
+		location string
+		body     string
+	}{
+		{SlashRedirectAdd, "GET", "/with?a=1", http.StatusMovedPermanently, "/with/?a=1", ""},
+		{SlashRedirectAdd, "POST", "/with", http.StatusPermanentRedirect, "/with/", ""},
+		{SlashRedirectAdd, "GET", "/without/", http.StatusNotFound, "", ""},
+		{SlashRedirectAdd, "GET", "/with.json", http.StatusNotFound, "", ""},
+		{SlashStrict, "GET", "/with", http.StatusNotFound, "", ""},
+		{SlashRedirectRemove, "GET", "/without/?b=2", http.StatusMovedPermanently, "/without?b=2", ""},
+		{SlashRedirectRemove, "GET", "/with", http.StatusNotFound, "", ""},
+		{SlashMatchBoth, "GET", "/with", http.StatusOK, "", "/with"},
+		{SlashMatchBoth, "GET", "/without/", http.StatusOK, "", "/without/"},
+	}
+
+	for _, test := range tests {
+		r := New(nil).TrailingSlash(test.policy)
+		r.Get("/with/", handler)
+		r.Post("/with/", handler)
+		r.Get("/without", handler)
+
+		req := httptest.NewRequest(test.method, test.target, nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+
+		if status := res.Code; status != test.status {
+			t.Errorf("%s %s: handler returned wrong status code: got %v want %v", test.method, test.target, status, test.status)
+		}
+		if location := res.Header().Get("Location"); location != test.location {
+			t.Errorf("%s %s: handler returned wrong location: got %v want %v", test.method, test.target, location, test.location)
+		}
+		if test.body != "" && res.Body.String() != test.body {
+			t.Errorf("%s %s: handler returned unexpected body: got %v want %v", test.method, test.target, res.Body.String(), test.body)
+		}
+	}
+}
+
+func TestCleanPathAndIgnoreCase(t *testing.T) {
+	r := New(nil).CleanPath(true).IgnoreCase(true)
+	r.Get("/users/:name/", func(ctx *Ctx) error {
+		return ctx.Res.Text(ctx.Req.Params.Get("name"))
+	})
+
+	mux := r.Handler()
+
+	req := httptest.NewRequest("GET", "/a//b/../users/Jack/?x=1", nil)
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if location := res.Header().Get("Location"); location != "/a/users/Jack/?x=1" {
+		t.Errorf("handler returned wrong location: got %v want %v", location, "/a/users/Jack/?x=1")
+	}
+
+	req = httptest.NewRequest("GET", "/USERS/Jack/", nil)
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if res.Body.String() != "Jack" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "Jack")
+	}
+}
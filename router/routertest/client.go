@@ -0,0 +1,179 @@
+package routertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Клиент для тестирования обработчиков роутера
+// Избавляет от повторения httptest.NewRequest/NewRecorder/ServeHTTP в каждом тесте
+//
+// c := routertest.New(r.Handler())
+// c.Get("/users/5").WithCookie("lang", "ru").Expect(t).Status(200).JSONPath("$.id", 5)
+type Client struct {
+	handler http.Handler
+}
+
+func New(h http.Handler) *Client {
+	return &Client{h}
+}
+
+func (self *Client) Get(target string) *Request {
+	return self.Do(http.MethodGet, target)
+}
+
+func (self *Client) Post(target string) *Request {
+	return self.Do(http.MethodPost, target)
+}
+
+func (self *Client) Put(target string) *Request {
+	return self.Do(http.MethodPut, target)
+}
+
+func (self *Client) Head(target string) *Request {
+	return self.Do(http.MethodHead, target)
+}
+
+func (self *Client) Delete(target string) *Request {
+	return self.Do(http.MethodDelete, target)
+}
+
+// Запрос с произвольным методом
+func (self *Client) Do(method, target string) *Request {
+	return &Request{
+		handler: self.handler,
+		method:  method,
+		target:  target,
+		header:  make(http.Header),
+	}
+}
+
+// Строитель тестового запроса
+type Request struct {
+	handler http.Handler
+	method  string
+	target  string
+	header  http.Header
+	cookies []*http.Cookie
+	body    []byte
+	ctx     context.Context
+	err     error
+}
+
+// Добавляет заголовок
+func (self *Request) WithHeader(key, value string) *Request {
+	self.header.Add(key, value)
+	return self
+}
+
+// Добавляет куку без подписи, как если бы она была установлена на клиенте
+func (self *Request) WithCookie(name, value string) *Request {
+	self.cookies = append(self.cookies, &http.Cookie{Name: name, Value: value})
+	return self
+}
+
+// Добавляет куку подписанную так же, как это делает ctx.Res.Cookies.Set
+// Значение будет доступно в обработчике через ctx.Req.Cookies.Get
+func (self *Request) WithSignedCookie(name, value string) *Request {
+	self.cookies = append(self.cookies, SignedCookie(name, value))
+	return self
+}
+
+// Тело запроса в виде JSON
+func (self *Request) WithJSON(obj interface{}) *Request {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		self.err = err
+		return self
+	}
+	self.body = body
+	self.header.Set("Content-Type", "application/json")
+	return self
+}
+
+// Тело запроса в виде формы application/x-www-form-urlencoded
+func (self *Request) WithForm(form url.Values) *Request {
+	self.body = []byte(form.Encode())
+	self.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return self
+}
+
+// Произвольное тело запроса
+func (self *Request) WithBody(body io.Reader) *Request {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		self.err = err
+		return self
+	}
+	self.body = data
+	return self
+}
+
+// Контекст запроса
+func (self *Request) WithContext(ctx context.Context) *Request {
+	self.ctx = ctx
+	return self
+}
+
+// Собирает *http.Request
+func (self *Request) Build() (*http.Request, error) {
+	if self.err != nil {
+		return nil, self.err
+	}
+	var body io.Reader
+	if self.body != nil {
+		body = bytes.NewReader(self.body)
+	}
+	req := httptest.NewRequest(self.method, self.target, body)
+	for k, v := range self.header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	for _, c := range self.cookies {
+		req.AddCookie(c)
+	}
+	if self.ctx != nil {
+		req = req.WithContext(self.ctx)
+	}
+	return req, nil
+}
+
+// Выполняет запрос и возвращает записанный ответ
+func (self *Request) Do() (*httptest.ResponseRecorder, error) {
+	req, err := self.Build()
+	if err != nil {
+		return nil, err
+	}
+	res := httptest.NewRecorder()
+	self.handler.ServeHTTP(res, req)
+	return res, nil
+}
+
+// Выполняет запрос и возвращает набор проверок ответа
+func (self *Request) Expect(t testing.TB) *Expect {
+	t.Helper()
+	res, err := self.Do()
+	if err != nil {
+		t.Fatalf("%s %s: can't build request: %s", self.method, self.target, err)
+	}
+	return &Expect{
+		t:      t,
+		name:   self.method + " " + self.target,
+		res:    res,
+		header: res.Result().Header,
+	}
+}
+
+// Значение заголовка без учета параметров, например charset у Content-Type
+func mediaType(value string) string {
+	if i := strings.Index(value, ";"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
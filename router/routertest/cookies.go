@@ -0,0 +1,20 @@
+package routertest
+
+import (
+	"net/http"
+
+	"github.com/AlexanderGrom/componenta/router"
+)
+
+// Кука подписанная так же, как это делает ctx.Res.Cookies.Set
+func SignedCookie(name, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:  name,
+		Value: router.SignCookie(value),
+	}
+}
+
+// Проверяет подпись куки из ответа и возвращает исходное значение
+func ReadSignedCookie(cookie *http.Cookie) (string, bool) {
+	return router.VerifyCookie(cookie.Value)
+}
@@ -0,0 +1,43 @@
+package routertest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/AlexanderGrom/componenta/router"
+)
+
+// Создает контекст для юнит-тестирования отдельного Handler или Middleware
+// Ответ записывается в возвращаемый ResponseRecorder
+func NewCtx(method, target string, body io.Reader) (*router.Ctx, *httptest.ResponseRecorder) {
+	return NewCtxFromRequest(httptest.NewRequest(method, target, body))
+}
+
+// Создает контекст из готового запроса
+func NewCtxFromRequest(r *http.Request) (*router.Ctx, *httptest.ResponseRecorder) {
+	res := httptest.NewRecorder()
+	return router.NewCtx(res, r), res
+}
+
+// Вызывает Handler с параметрами URL, как если бы их выделил роутер
+func Handle(h router.Handler, ctx *router.Ctx, params router.URLParams) error {
+	if params != nil {
+		ctx.Req.Params = params
+	}
+	return h(ctx)
+}
+
+// Вызывает Middleware, передавая ему next, который запускает переданный Handler
+// Возвращает признак того, что middleware вызвал next, и ошибку цепочки
+func RunMiddleware(mw router.Middleware, ctx *router.Ctx, next router.Handler) (bool, error) {
+	called := false
+	err := mw(ctx, func() error {
+		called = true
+		if next != nil {
+			return next(ctx)
+		}
+		return nil
+	})
+	return called, err
+}
@@ -0,0 +1,200 @@
+package routertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Набор проверок ответа
+// Каждая проверка при несовпадении вызывает t.Errorf и возвращает Expect для продолжения цепочки
+type Expect struct {
+	t      testing.TB
+	name   string
+	res    *httptest.ResponseRecorder
+	header http.Header
+}
+
+// Записанный ответ для проверок, которых нет в Expect
+func (self *Expect) Recorder() *httptest.ResponseRecorder {
+	return self.res
+}
+
+// Проверка кода ответа
+func (self *Expect) Status(code int) *Expect {
+	self.t.Helper()
+	if self.res.Code != code {
+		self.t.Errorf("%s: wrong status code: got %v want %v", self.name, self.res.Code, code)
+	}
+	return self
+}
+
+// Проверка заголовка ответа
+func (self *Expect) Header(key, value string) *Expect {
+	self.t.Helper()
+	if got := self.header.Get(key); got != value {
+		self.t.Errorf("%s: wrong header %s: got %q want %q", self.name, key, got, value)
+	}
+	return self
+}
+
+// Проверка Content-Type без учета параметров
+func (self *Expect) ContentType(value string) *Expect {
+	self.t.Helper()
+	if got := mediaType(self.header.Get("Content-Type")); got != value {
+		self.t.Errorf("%s: wrong content type: got %q want %q", self.name, got, value)
+	}
+	return self
+}
+
+// Проверка адреса перенаправления
+func (self *Expect) Redirect(location string) *Expect {
+	self.t.Helper()
+	if got := self.header.Get("Location"); got != location {
+		self.t.Errorf("%s: wrong redirect location: got %q want %q", self.name, got, location)
+	}
+	return self
+}
+
+// Проверка тела ответа целиком
+func (self *Expect) Body(body string) *Expect {
+	self.t.Helper()
+	if got := self.res.Body.String(); got != body {
+		self.t.Errorf("%s: unexpected body: got %q want %q", self.name, got, body)
+	}
+	return self
+}
+
+// Проверка вхождения строки в тело ответа
+func (self *Expect) BodyContains(s string) *Expect {
+	self.t.Helper()
+	if got := self.res.Body.String(); !strings.Contains(got, s) {
+		self.t.Errorf("%s: body %q does not contain %q", self.name, got, s)
+	}
+	return self
+}
+
+// Проверка тела ответа как JSON
+// Сравнение идет по значениям, поэтому порядок ключей и форматирование не важны
+func (self *Expect) JSON(obj interface{}) *Expect {
+	self.t.Helper()
+	got, err := self.decode()
+	if err != nil {
+		self.t.Errorf("%s: %s", self.name, err)
+		return self
+	}
+	want, err := normalize(obj)
+	if err != nil {
+		self.t.Errorf("%s: can't encode expected value: %s", self.name, err)
+		return self
+	}
+	if !reflect.DeepEqual(got, want) {
+		self.t.Errorf("%s: unexpected json: got %s want %s", self.name, self.res.Body.String(), marshal(want))
+	}
+	return self
+}
+
+// Проверка значения по пути в JSON ответе
+// Поддерживаются пути вида $.user.tags[0], $["key"].id
+func (self *Expect) JSONPath(path string, value interface{}) *Expect {
+	self.t.Helper()
+	doc, err := self.decode()
+	if err != nil {
+		self.t.Errorf("%s: %s", self.name, err)
+		return self
+	}
+	got, err := lookup(doc, path)
+	if err != nil {
+		self.t.Errorf("%s: %s", self.name, err)
+		return self
+	}
+	want, err := normalize(value)
+	if err != nil {
+		self.t.Errorf("%s: can't encode expected value: %s", self.name, err)
+		return self
+	}
+	if !reflect.DeepEqual(got, want) {
+		self.t.Errorf("%s: unexpected value at %s: got %s want %s", self.name, path, marshal(got), marshal(want))
+	}
+	return self
+}
+
+// Проверка сырого значения установленной куки
+func (self *Expect) Cookie(name, value string) *Expect {
+	self.t.Helper()
+	cookie := self.cookie(name)
+	if cookie == nil {
+		self.t.Errorf("%s: cookie %s is not set", self.name, name)
+		return self
+	}
+	if cookie.Value != value {
+		self.t.Errorf("%s: wrong cookie %s: got %q want %q", self.name, name, cookie.Value, value)
+	}
+	return self
+}
+
+// Проверка куки установленной через ctx.Res.Cookies.Set
+// Подпись проверяется, сравнивается исходное значение
+func (self *Expect) SignedCookie(name, value string) *Expect {
+	self.t.Helper()
+	cookie := self.cookie(name)
+	if cookie == nil {
+		self.t.Errorf("%s: cookie %s is not set", self.name, name)
+		return self
+	}
+	got, ok := ReadSignedCookie(cookie)
+	if !ok {
+		self.t.Errorf("%s: cookie %s has invalid signature", self.name, name)
+		return self
+	}
+	if got != value {
+		self.t.Errorf("%s: wrong cookie %s: got %q want %q", self.name, name, got, value)
+	}
+	return self
+}
+
+func (self *Expect) cookie(name string) *http.Cookie {
+	for _, c := range self.res.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (self *Expect) decode() (interface{}, error) {
+	var doc interface{}
+	if err := json.Unmarshal(self.res.Body.Bytes(), &doc); err != nil {
+		return nil, &jsonError{"body is not a valid json", err}
+	}
+	return doc, nil
+}
+
+// Приводим ожидаемое значение к виду, в котором json.Unmarshal отдает данные,
+// чтобы 5 и float64(5), структуры и map сравнивались одинаково
+func normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+func marshal(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+type jsonError struct {
+	msg string
+	err error
+}
+
+func (self *jsonError) Error() string {
+	return self.msg + ": " + self.err.Error()
+}
@@ -0,0 +1,74 @@
+package routertest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Находит значение в разобранном JSON документе по простому пути
+// Поддерживается корень $, обращение к ключам через точку или ["key"] и индексы [n]
+func lookup(doc interface{}, path string) (interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path %q must start with $", path)
+	}
+	current := doc
+	rest := path[1:]
+	for len(rest) > 0 {
+		var key string
+		var index = -1
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+			if key == "" {
+				return nil, fmt.Errorf("json path %q: empty key", path)
+			}
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("json path %q: unclosed bracket", path)
+			}
+			segment := rest[1:end]
+			rest = rest[end+1:]
+			if len(segment) >= 2 && (segment[0] == '"' || segment[0] == '\'') && segment[len(segment)-1] == segment[0] {
+				key = segment[1 : len(segment)-1]
+			} else {
+				n, err := strconv.Atoi(segment)
+				if err != nil {
+					return nil, fmt.Errorf("json path %q: bad index %q", path, segment)
+				}
+				index = n
+			}
+		default:
+			return nil, fmt.Errorf("json path %q: unexpected %q", path, rest[0])
+		}
+
+		if index >= 0 {
+			list, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("json path %q: not an array before [%d]", path, index)
+			}
+			if index >= len(list) {
+				return nil, fmt.Errorf("json path %q: index %d out of range", path, index)
+			}
+			current = list[index]
+			continue
+		}
+
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("json path %q: not an object before %q", path, key)
+		}
+		value, ok := obj[key]
+		if !ok {
+			return nil, fmt.Errorf("json path %q: key %q not found", path, key)
+		}
+		current = value
+	}
+	return current, nil
+}
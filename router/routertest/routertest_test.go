@@ -0,0 +1,111 @@
+package routertest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/AlexanderGrom/componenta/router"
+)
+
+func TestClientJSON(t *testing.T) {
+	r := router.New(nil)
+	r.Post("/users/:id", func(ctx *router.Ctx) error {
+		return ctx.Res.Json(map[string]interface{}{
+			"id":   5,
+			"name": ctx.Req.Params.Get("id"),
+			"lang": ctx.Req.Cookies.Get("lang"),
+			"tags": []string{"a", "b"},
+		})
+	})
+
+	New(r.Handler()).Post("/users/bob").
+		WithSignedCookie("lang", "ru").
+		WithJSON(map[string]string{"x": "y"}).
+		Expect(t).
+		Status(http.StatusOK).
+		ContentType("application/json").
+		JSONPath("$.id", 5).
+		JSONPath("$.name", "bob").
+		JSONPath("$.lang", "ru").
+		JSONPath("$.tags[1]", "b").
+		JSONPath(`$["tags"][0]`, "a")
+}
+
+func TestClientSignedCookie(t *testing.T) {
+	r := router.New(nil)
+	r.Get("/login", func(ctx *router.Ctx) error {
+		ctx.Res.Cookies.Set("user", "Alexander", 100)
+		return ctx.Res.Redirect("/home", http.StatusFound)
+	})
+
+	New(r.Handler()).Get("/login").
+		Expect(t).
+		Status(http.StatusFound).
+		Redirect("/home").
+		SignedCookie("user", "Alexander")
+}
+
+func TestClientRawCookie(t *testing.T) {
+	r := router.New(nil)
+	r.Get("/", func(ctx *router.Ctx) error {
+		return ctx.Res.Text(ctx.Req.Cookies.Get("lang") + "|" + ctx.Req.Cookies.GetRaw("lang").Value)
+	})
+
+	New(r.Handler()).Get("/").WithCookie("lang", "ru").Expect(t).Body("|ru")
+}
+
+func TestHandle(t *testing.T) {
+	ctx, res := NewCtx("GET", "/users/7", nil)
+	h := func(ctx *router.Ctx) error {
+		return ctx.Res.Text("user " + ctx.Req.Params.Get("id"))
+	}
+	if err := Handle(h, ctx, router.URLParams{"id": "7"}); err != nil {
+		t.Fatal(err)
+	}
+	if res.Body.String() != "user 7" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "user 7")
+	}
+}
+
+func TestRunMiddleware(t *testing.T) {
+	deny := func(ctx *router.Ctx, next router.Next) error {
+		if ctx.Req.Header.Get("Authorization") == "" {
+			return ctx.Res.Status(http.StatusUnauthorized)
+		}
+		return next()
+	}
+
+	ctx, res := NewCtx("GET", "/", nil)
+	called, err := RunMiddleware(deny, ctx, nil)
+	if err != nil || called {
+		t.Errorf("middleware must stop the chain: called %v, err %v", called, err)
+	}
+	if res.Code != http.StatusUnauthorized {
+		t.Errorf("middleware returned wrong status code: got %v want %v", res.Code, http.StatusUnauthorized)
+	}
+
+	ctx, _ = NewCtx("GET", "/", nil)
+	ctx.Req.Header.Set("Authorization", "token")
+	fail := errors.New("handler")
+	called, err = RunMiddleware(deny, ctx, func(*router.Ctx) error { return fail })
+	if !called || err != fail {
+		t.Errorf("middleware must call next: called %v, err %v", called, err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": []interface{}{map[string]interface{}{"b": "c"}},
+	}
+	v, err := lookup(doc, "$.a[0].b")
+	if err != nil || v != "c" {
+		t.Errorf("unexpected lookup result: %v, %v", v, err)
+	}
+	if _, err := lookup(doc, "$.a[1]"); err == nil {
+		t.Errorf("expected out of range error")
+	}
+	if _, err := lookup(doc, "a"); err == nil {
+		t.Errorf("expected error for path without $")
+	}
+}
@@ -0,0 +1,313 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Объем памяти для ParseMultipartForm, остальное сохраняется во временные файлы
+var MultipartMemory int64 = 32 << 20
+
+var (
+	ErrFileTooLarge    = errors.New("Uploaded file too large")
+	ErrUploadTooLarge  = errors.New("Upload too large")
+	ErrFileTypeDenied  = errors.New("Uploaded file type not allowed")
+	ErrNotMultipart    = errors.New("Request is not multipart")
+	ErrUploadCompleted = errors.New("Upload completed")
+)
+
+// Файлы формы по имени поля
+// Временные файлы удаляются после завершения обработки запроса
+func (self *Request) Files(name string) ([]*multipart.FileHeader, error) {
+	if self.MultipartForm == nil {
+		if err := self.ParseMultipartForm(MultipartMemory); err != nil {
+			return nil, err
+		}
+		self.onCleanup(func() {
+			self.MultipartForm.RemoveAll()
+		})
+	}
+	return self.MultipartForm.File[name], nil
+}
+
+// Первый файл формы по имени поля
+func (self *Request) File(name string) (*multipart.FileHeader, error) {
+	files, err := self.Files(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	return files[0], nil
+}
+
+// Потоковое чтение multipart запроса без буферизации в памяти
+//
+//	upload, err := ctx.Req.Upload(router.UploadLimits{
+//	    MaxFileSize: 5 << 20,
+//	    Allow:       []string{"image/png", "image/jpeg"},
+//	})
+//
+//	for {
+//	    part, err := upload.Next()
+//	    if err == router.ErrUploadCompleted {
+//	        break
+//	    }
+//	    ...
+//	    path, err := part.Save("/var/uploads")
+//	}
+func (self *Request) Upload(limits UploadLimits) (*Upload, error) {
+	reader, err := self.MultipartReader()
+	if err != nil {
+		if err == http.ErrNotMultipart {
+			return nil, ErrNotMultipart
+		}
+		return nil, err
+	}
+	return &Upload{
+		req:    self,
+		reader: reader,
+		limits: limits,
+	}, nil
+}
+
+// Выполнение отложенных действий после обработки запроса
+func (self *Request) cleanup() {
+	for _, fn := range self.cleanups {
+		fn()
+	}
+	self.cleanups = nil
+}
+
+func (self *Request) onCleanup(fn func()) {
+	self.cleanups = append(self.cleanups, fn)
+}
+
+// Ограничения загрузки, нулевые значения означают отсутствие ограничения
+type UploadLimits struct {
+	// Максимальный размер одного файла
+	MaxFileSize int64
+	// Максимальный суммарный размер всех частей: файлов, полей и пропущенных частей
+	MaxTotalSize int64
+	// Разрешенные MIME типы, определяемые по содержимому файла: image/png, image/*
+	Allow []string
+}
+
+// Потоковый итератор по частям multipart запроса
+type Upload struct {
+	req    *Request
+	reader *multipart.Reader
+	limits UploadLimits
+	total  int64
+	part   *Part
+}
+
+// Следующая часть запроса
+// По окончании возвращает ErrUploadCompleted
+func (self *Upload) Next() (*Part, error) {
+	// Непрочитанный остаток предыдущей части учитывается в MaxTotalSize
+	if self.part != nil {
+		_, err := io.Copy(io.Discard, self.part)
+		self.part.part.Close()
+		self.part = nil
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	part, err := self.reader.NextPart()
+	if err == io.EOF {
+		return nil, ErrUploadCompleted
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := &Part{
+		upload: self,
+		part:   part,
+	}
+	self.part = p
+
+	if part.FileName() == "" {
+		return p, nil
+	}
+
+	// Тип файла определяется по первым 512 байтам, заголовку клиента не доверяем
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	p.head = head[:n]
+	p.mime = http.DetectContentType(p.head)
+
+	if !self.allowed(p.mime) {
+		return nil, ErrFileTypeDenied
+	}
+
+	if err := self.count(p, int64(n)); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Учет прочитанных байт с проверкой ограничений
+func (self *Upload) count(p *Part, n int64) error {
+	p.size += n
+	self.total += n
+	if self.limits.MaxFileSize > 0 && p.size > self.limits.MaxFileSize && p.FileName() != "" {
+		return ErrFileTooLarge
+	}
+	if self.limits.MaxTotalSize > 0 && self.total > self.limits.MaxTotalSize {
+		return ErrUploadTooLarge
+	}
+	return nil
+}
+
+func (self *Upload) allowed(mimetype string) bool {
+	if len(self.limits.Allow) == 0 {
+		return true
+	}
+	mimetype, _, _ = mime.ParseMediaType(mimetype)
+	for _, allow := range self.limits.Allow {
+		if allow == mimetype {
+			return true
+		}
+		if strings.HasSuffix(allow, "/*") && strings.HasPrefix(mimetype, allow[:len(allow)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Часть multipart запроса: обычное поле или файл
+type Part struct {
+	upload *Upload
+	part   *multipart.Part
+	head   []byte
+	mime   string
+	size   int64
+}
+
+// Имя поля формы
+func (self *Part) FormName() string {
+	return self.part.FormName()
+}
+
+// Имя файла от клиента, пустое для обычных полей
+// Для сохранения на диск не используется
+func (self *Part) FileName() string {
+	return self.part.FileName()
+}
+
+// MIME тип определенный по содержимому
+func (self *Part) ContentType() string {
+	return self.mime
+}
+
+// Количество прочитанных байт
+func (self *Part) Size() int64 {
+	return self.size
+}
+
+// Чтение содержимого части с соблюдением ограничений
+func (self *Part) Read(b []byte) (int, error) {
+	if len(self.head) > 0 {
+		n := copy(b, self.head)
+		self.head = self.head[n:]
+		return n, nil
+	}
+	n, err := self.part.Read(b)
+	if n > 0 {
+		if err := self.upload.count(self, int64(n)); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+// Сохранение файла в каталог dir под сгенерированным именем
+// Расширение берется из MIME типа, определенного по содержимому
+// Возвращает путь к сохраненному файлу, при ошибке частично записанный файл удаляется
+func (self *Part) Save(dir string) (string, error) {
+	if self.FileName() == "" {
+		return "", fmt.Errorf("router: part %q is not a file", self.FormName())
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return "", err
+	}
+	name += extension(self.mime)
+
+	filename := filepath.Join(dir, name)
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(file, self)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(filename)
+		return "", err
+	}
+
+	return filename, nil
+}
+
+// Сохранение файла во временный каталог
+// Файл будет удален после завершения обработки запроса
+func (self *Part) SaveTemp() (string, error) {
+	filename, err := self.Save(os.TempDir())
+	if err != nil {
+		return "", err
+	}
+	self.upload.req.onCleanup(func() {
+		os.Remove(filename)
+	})
+	return filename, nil
+}
+
+// Привычные расширения для распространенных типов,
+// mime.ExtensionsByType отдает их в алфавитном порядке: .jfif вместо .jpg
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+func extension(mimetype string) string {
+	mimetype, _, _ = mime.ParseMediaType(mimetype)
+	if ext, ok := extensions[mimetype]; ok {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(mimetype); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,184 @@
+package router
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func newMultipartRequest(t *testing.T, target string, files map[string][]byte) *http.Request {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	w.WriteField("title", "avatar")
+	for name, data := range files {
+		part, err := w.CreateFormFile(name, "../../"+name+".exe")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	w.Close()
+	req := httptest.NewRequest("POST", target, body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestUploadSave(t *testing.T) {
+	dir := t.TempDir()
+	var saved string
+
+	r := New(nil)
+	r.Post("/upload", func(ctx *Ctx) error {
+		upload, err := ctx.Req.Upload(UploadLimits{
+			MaxFileSize: 1024,
+			Allow:       []string{"image/*"},
+		})
+		if err != nil {
+			return err
+		}
+		for {
+			part, err := upload.Next()
+			if err == ErrUploadCompleted {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if part.FileName() == "" {
+				continue
+			}
+			if saved, err = part.Save(dir); err != nil {
+				return err
+			}
+		}
+		return ctx.Res.Text("ok")
+	})
+
+	req := newMultipartRequest(t, "/upload", map[string][]byte{"avatar": append(pngHeader, make([]byte, 100)...)})
+	res := httptest.NewRecorder()
+	r.Handler().ServeHTTP(res, req)
+
+	if res.Body.String() != "ok" {
+		t.Fatalf("handler returned unexpected body: got %v want %v", res.Body.String(), "ok")
+	}
+	if filepath.Dir(saved) != dir || filepath.Ext(saved) != ".png" {
+		t.Errorf("file saved with unexpected name: %v", saved)
+	}
+	if info, err := os.Stat(saved); err != nil || info.Size() != int64(len(pngHeader)+100) {
+		t.Errorf("file saved with unexpected size: %v, %v", info, err)
+	}
+}
+
+func TestUploadLimits(t *testing.T) {
+	tests := []struct {
+		limits UploadLimits
+		data   []byte
+		err    error
+	}{
+		{UploadLimits{Allow: []string{"image/png"}}, []byte("plain text"), ErrFileTypeDenied},
+		{UploadLimits{MaxFileSize: 10}, append(pngHeader, make([]byte, 100)...), ErrFileTooLarge},
+		{UploadLimits{MaxTotalSize: 600}, append(pngHeader, make([]byte, 1000)...), ErrUploadTooLarge},
+	}
+
+	for _, test := range tests {
+		req := newMultipartRequest(t, "/upload", map[string][]byte{"file": test.data})
+		ctx := NewCtx(httptest.NewRecorder(), req)
+		upload, err := ctx.Req.Upload(test.limits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got error
+		for got == nil {
+			var part *Part
+			part, got = upload.Next()
+			if got == nil && part.FileName() != "" {
+				_, got = part.Save(t.TempDir())
+			}
+		}
+		if got != test.err {
+			t.Errorf("unexpected upload error: got %v want %v", got, test.err)
+		}
+	}
+}
+
+func TestUploadTotalSize(t *testing.T) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	w.WriteField("title", strings.Repeat("a", 400))
+	part, _ := w.CreateFormFile("skipped", "skipped.png")
+	part.Write(append(pngHeader, make([]byte, 400)...))
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/upload", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	ctx := NewCtx(httptest.NewRecorder(), req)
+
+	upload, err := ctx.Req.Upload(UploadLimits{MaxTotalSize: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got error
+	for got == nil {
+		_, got = upload.Next()
+	}
+	if got != ErrUploadTooLarge {
+		t.Errorf("unexpected upload error: got %v want %v", got, ErrUploadTooLarge)
+	}
+}
+
+func TestFilesCleanup(t *testing.T) {
+	var tmp string
+
+	r := New(nil)
+	r.Post("/upload", func(ctx *Ctx) error {
+		file, err := ctx.Req.File("avatar")
+		if err != nil {
+			return err
+		}
+		return ctx.Res.Text(file.Filename)
+	})
+	r.Post("/temp", func(ctx *Ctx) error {
+		upload, err := ctx.Req.Upload(UploadLimits{})
+		if err != nil {
+			return err
+		}
+		for {
+			part, err := upload.Next()
+			if err != nil {
+				break
+			}
+			if part.FileName() != "" {
+				if tmp, err = part.SaveTemp(); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = os.Stat(tmp)
+		return err
+	})
+
+	mux := r.Handler()
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, newMultipartRequest(t, "/upload", map[string][]byte{"avatar": pngHeader}))
+	if res.Body.String() != "avatar.exe" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "avatar.exe")
+	}
+
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, newMultipartRequest(t, "/temp", map[string][]byte{"avatar": pngHeader}))
+	if tmp == "" {
+		t.Fatal("temp file was not saved")
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Errorf("temp file must be removed after request: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package router
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Шаблонизатор для ctx.Res.Render
+type Renderer interface {
+	Render(w io.Writer, r *http.Request, name string, data interface{}) error
+}
+
+// Шаблонизатор на основе html/template
+//
+// Шаблоны загружаются из fs.FS, поэтому подходит как каталог на диске, так и embed.FS.
+// Имя шаблона - путь к файлу без расширения: "users/show" -> users/show.html
+// Все файлы из каталога partials доступны в каждом шаблоне: {{template "partials/nav" .}}
+// Если задан layout, рендерится он, а страница определяет его блоки: {{define "content"}}...{{end}}
+//
+// view := router.NewView(os.DirFS("views")).Layout("layouts/main").Reload(debug)
+// view.Funcs(template.FuncMap{"url": r.URL})
+// r.SetRenderer(view)
+type View struct {
+	fsys     fs.FS
+	ext      string
+	layout   string
+	partials string
+	reload   bool
+	funcs    template.FuncMap
+	csrf     func(*http.Request) string
+	mutex    sync.RWMutex
+	cache    map[string]*template.Template
+}
+
+var _ Renderer = (*View)(nil)
+
+func NewView(fsys fs.FS) *View {
+	return &View{
+		fsys:     fsys,
+		ext:      ".html",
+		partials: "partials",
+		funcs:    template.FuncMap{},
+		cache:    make(map[string]*template.Template),
+	}
+}
+
+// Helper для загрузки шаблонов из каталога на диске
+func NewViewDir(dir string) *View {
+	return NewView(os.DirFS(dir))
+}
+
+// Расширение файлов шаблонов, по умолчанию .html
+func (self *View) Ext(ext string) *View {
+	self.ext = ext
+	self.flush()
+	return self
+}
+
+// Шаблон-обертка для всех страниц, пустая строка отключает layout
+func (self *View) Layout(name string) *View {
+	self.layout = name
+	self.flush()
+	return self
+}
+
+// Каталог с общими частями шаблонов, по умолчанию partials
+func (self *View) Partials(dir string) *View {
+	self.partials = dir
+	self.flush()
+	return self
+}
+
+// Перечитывать шаблоны при каждом рендеринге, удобно в режиме разработки
+func (self *View) Reload(enable bool) *View {
+	self.reload = enable
+	self.flush()
+	return self
+}
+
+// Дополнительные функции шаблонов
+func (self *View) Funcs(funcs template.FuncMap) *View {
+	for k, v := range funcs {
+		self.funcs[k] = v
+	}
+	self.flush()
+	return self
+}
+
+// Источник CSRF токена для функций csrf и csrfField
+// csrfField выводит <input type="hidden" name="csrf_token" value="...">
+func (self *View) CSRF(fn func(*http.Request) string) *View {
+	self.csrf = fn
+	self.flush()
+	return self
+}
+
+func (self *View) Render(w io.Writer, r *http.Request, name string, data interface{}) error {
+	tmpl, err := self.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	if self.csrf != nil {
+		// Закешированный шаблон не исполняется напрямую, чтобы его можно было клонировать
+		tmpl, err = tmpl.Clone()
+		if err != nil {
+			return fmt.Errorf("router: template %q: %s", name, err)
+		}
+		token := self.csrf(r)
+		tmpl.Funcs(template.FuncMap{
+			"csrf": func() string {
+				return token
+			},
+			"csrfField": func() template.HTML {
+				return template.HTML(`<input type="hidden" name="csrf_token" value="` + template.HTMLEscapeString(token) + `">`)
+			},
+		})
+	}
+
+	entry := name
+	if self.layout != "" {
+		entry = self.layout
+	}
+	return tmpl.ExecuteTemplate(w, entry, data)
+}
+
+// Шаблон из кеша или разобранный заново
+func (self *View) lookup(name string) (*template.Template, error) {
+	if self.reload {
+		return self.parse(name)
+	}
+
+	self.mutex.RLock()
+	tmpl, ok := self.cache[name]
+	self.mutex.RUnlock()
+	if ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := self.parse(name)
+	if err != nil {
+		return nil, err
+	}
+
+	self.mutex.Lock()
+	self.cache[name] = tmpl
+	self.mutex.Unlock()
+
+	return tmpl, nil
+}
+
+// Разбор набора шаблонов для страницы: partials, layout и сама страница
+func (self *View) parse(name string) (*template.Template, error) {
+	tmpl := template.New("").Funcs(template.FuncMap{
+		"csrf": func() string {
+			return ""
+		},
+		"csrfField": func() template.HTML {
+			return ""
+		},
+	}).Funcs(self.funcs)
+
+	if self.partials != "" {
+		err := fs.WalkDir(self.fsys, self.partials, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || path.Ext(p) != self.ext {
+				return nil
+			}
+			return self.parseFile(tmpl, strings.TrimSuffix(p, self.ext))
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("router: partials: %s", err)
+		}
+	}
+
+	if self.layout != "" {
+		if err := self.parseFile(tmpl, self.layout); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := self.parseFile(tmpl, name); err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+func (self *View) parseFile(tmpl *template.Template, name string) error {
+	src, err := fs.ReadFile(self.fsys, name+self.ext)
+	if err != nil {
+		return fmt.Errorf("router: template %q: %s", name, err)
+	}
+	if _, err := tmpl.New(name).Parse(string(src)); err != nil {
+		return fmt.Errorf("router: template %q: %s", name, err)
+	}
+	return nil
+}
+
+// Сброс кеша после изменения настроек
+func (self *View) flush() {
+	self.mutex.Lock()
+	self.cache = make(map[string]*template.Template)
+	self.mutex.Unlock()
+}
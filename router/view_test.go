@@ -0,0 +1,107 @@
+package router
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRender(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/main.html":  {Data: []byte(`<main>{{template "partials/nav" .}}{{block "content" .}}{{end}}</main>`)},
+		"partials/nav.html":  {Data: []byte(`<nav>{{.Title}}</nav>`)},
+		"users/show.html":    {Data: []byte(`{{define "content"}}<a href="{{url "user" "id" .ID}}">{{.Name}}</a>{{csrfField}}{{end}}`)},
+		"users/plain.html":   {Data: []byte(`{{.Name}}`)},
+		"users/broken.html":  {Data: []byte(`{{.Name`)},
+		"users/unknown.html": {Data: []byte(`{{define "content"}}{{.Missing.Field}}{{end}}`)},
+	}
+
+	r := New(nil)
+	view := NewView(fsys).Layout("layouts/main").CSRF(func(req *http.Request) string {
+		return req.Header.Get("X-Token")
+	})
+	view.Funcs(template.FuncMap{"url": r.URL})
+	r.SetRenderer(view)
+
+	r.Get("/users/:id", func(ctx *Ctx) error {
+		return ctx.Res.Render(http.StatusCreated, "users/show", map[string]string{
+			"Title": "Users",
+			"ID":    ctx.Req.Params.Get("id"),
+			"Name":  "<Jack>",
+		})
+	}).Name("user")
+
+	r.Get("/broken", func(ctx *Ctx) error {
+		return ctx.Res.Render(http.StatusOK, "users/broken", nil)
+	})
+
+	mux := r.Handler()
+
+	req := httptest.NewRequest("GET", "/users/5", nil)
+	req.Header.Set("X-Token", "secret")
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if status := res.Code; status != http.StatusCreated {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+
+	expected := `<main><nav>Users</nav><a href="/users/5">&lt;Jack&gt;</a><input type="hidden" name="csrf_token" value="secret"></main>`
+	if res.Body.String() != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), expected)
+	}
+
+	if ct := res.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("handler returned wrong content type: got %v", ct)
+	}
+
+	req = httptest.NewRequest("GET", "/broken", nil)
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, req)
+
+	if res.Body.Len() != 0 {
+		t.Errorf("handler must not write body on template error: got %v", res.Body.String())
+	}
+}
+
+func TestRenderWithoutLayout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.tmpl": {Data: []byte(`Hello, {{.}}`)},
+	}
+
+	view := NewView(fsys).Ext(".tmpl").Reload(true)
+	res := httptest.NewRecorder()
+	ctx := NewCtx(res, httptest.NewRequest("GET", "/", nil))
+	ctx.Res.Renderer = view
+
+	if err := ctx.Res.Render(http.StatusOK, "index", "World"); err != nil {
+		t.Fatal(err)
+	}
+	if res.Body.String() != "Hello, World" {
+		t.Errorf("handler returned unexpected body: got %v want %v", res.Body.String(), "Hello, World")
+	}
+
+	ctx.Res.Renderer = nil
+	if err := ctx.Res.Render(http.StatusOK, "index", nil); err != ErrRendererNotDefined {
+		t.Errorf("expected ErrRendererNotDefined, got %v", err)
+	}
+}
+
+func TestRouteURL(t *testing.T) {
+	r := New(nil)
+	g := r.Group("/news")
+	g.Get("/:year/:slug", func(ctx *Ctx) error { return nil }).Name("news.show")
+
+	u, err := r.URL("news.show", "year", "2018", "slug", "hello world")
+	if err != nil || u != "/news/2018/hello%20world" {
+		t.Errorf("unexpected url: %v, %v", u, err)
+	}
+	if _, err := r.URL("news.show", "year", "2018"); err == nil {
+		t.Errorf("expected missing parameter error")
+	}
+	if _, err := r.URL("unknown"); err == nil {
+		t.Errorf("expected unknown route error")
+	}
+}
@@ -1,12 +1,16 @@
 package sqlx
 
 import (
+	"errors"
+	"reflect"
 	"sort"
+	"strings"
 )
 
 type glammarFunc func() glammar
 
 var driver glammarFunc
+var driverName string
 var drivers = map[string]glammarFunc{}
 
 type List []interface{}
@@ -32,12 +36,26 @@ func (self Data) Values() []interface{} {
 
 // Драйвер грамматики, который будет использован для построения запроса
 // Параметр name может принимать значения: mysql, postgres, sqlite3
+// Используется по умолчанию, если диалект не указан в sqlx.DataBase или Builder.ToSQL
 func Driver(name string) {
+	driver = lookupDriver(name)
+	driverName = name
+}
+
+func lookupDriver(name string) glammarFunc {
 	glammar, ok := drivers[name]
 	if !ok {
 		panic("sqlx: driver '" + name + "' not found")
 	}
-	driver = glammar
+	return glammar
+}
+
+// Имя диалекта с учетом драйвера по умолчанию
+func dialectName(name string) string {
+	if name == "" {
+		return driverName
+	}
+	return name
 }
 
 func registerDriver(name string, constructor glammarFunc) {
@@ -45,14 +63,14 @@ func registerDriver(name string, constructor glammarFunc) {
 }
 
 // Kарта значений для плейсехолдеров
-var bindings = []string{"values", "set", "select", "from", "join", "where", "having", "limit", "offset"}
+var bindings = []string{"with", "values", "upsert", "set", "select", "from", "join", "where", "having", "union", "limit", "offset", "returning"}
 
 // Карта значений для плейсехолдеров в зависимости от типа запроса
 var bindingsMap = map[string][]string{
-	"select": []string{"select", "from", "join", "where", "group", "having", "limit", "offset"},
-	"insert": []string{"values"},
-	"update": []string{"set", "where"},
-	"delete": []string{"where"},
+	"select": []string{"with", "select", "from", "join", "where", "group", "having", "union", "limit", "offset"},
+	"insert": []string{"with", "values", "upsert", "returning"},
+	"update": []string{"with", "set", "where", "returning"},
+	"delete": []string{"with", "where", "returning"},
 }
 
 // Создаем карту значений для плейсехолдеров
@@ -69,6 +87,13 @@ type Builder struct {
 	table      string
 	components *components
 	bindings   map[string][]interface{}
+	// Структуры InsertStruct для заполнения первичного ключа
+	structs []reflect.Value
+	primary *field
+	// Параметры CursorPaginate
+	cursor *cursorState
+	// Первая ошибка построения, возвращается при выполнении запроса
+	err error
 }
 
 func NewBuilder() *Builder {
@@ -83,20 +108,51 @@ func Table(table interface{}) *Builder {
 	return NewBuilder().From(table)
 }
 
+// Общее табличное выражение: WITH name AS ( ... )
+func (self *Builder) With(name string, builder *Builder) *Builder {
+	self.components.With = append(self.components.With, withComponent{
+		name:    name,
+		builder: builder,
+	})
+	self.bind("with", builder.rawData()...)
+	return self
+}
+
+// Рекурсивное табличное выражение:
+// WITH RECURSIVE name ( columns ) AS ( anchor UNION ALL recursive )
+func (self *Builder) WithRecursive(name string, columns []string, anchor *Builder, recursive *Builder) *Builder {
+	self.components.With = append(self.components.With, withComponent{
+		name:      name,
+		columns:   columns,
+		builder:   anchor,
+		recursive: recursive,
+	})
+	self.bind("with", anchor.rawData()...)
+	self.bind("with", recursive.rawData()...)
+	return self
+}
+
 func (self *Builder) Select(p ...interface{}) *Builder {
 	if self.kind != "" && self.kind != "select" {
 		return self
 	}
 	self.kind = "select"
 	self.components.Select = append(self.components.Select, p...)
-	for _, v := range p {
-		if exp, ok := v.(Expression); ok {
-			self.bind("select", exp.Data()...)
-		}
-	}
+	self.bind("select", clauseData(p...)...)
 	return self
 }
 
+// Выборка уникальных строк: SELECT DISTINCT
+func (self *Builder) Distinct() *Builder {
+	self.components.Distinct = []interface{}{true}
+	return self
+}
+
+// Подзапрос в Select: ( SELECT ... ) as alias
+func (self *Builder) SelectSub(builder *Builder, alias string) *Builder {
+	return self.Select(subSelect{builder, alias})
+}
+
 func (self *Builder) SelectRaw(exp string, bindings ...interface{}) *Builder {
 	return self.Select(Raw(exp, bindings...))
 }
@@ -130,7 +186,7 @@ func (self *Builder) fromSub(builder *Builder) {
 		kind:    "sub",
 		builder: builder,
 	})
-	self.bind("from", builder.Data()...)
+	self.bind("from", builder.rawData()...)
 }
 
 func (self *Builder) fromFun(callback func(*Builder)) {
@@ -140,7 +196,7 @@ func (self *Builder) fromFun(callback func(*Builder)) {
 		kind:    "sub",
 		builder: builder,
 	})
-	self.bind("from", builder.Data()...)
+	self.bind("from", builder.rawData()...)
 }
 
 func (self *Builder) fromExp(exp Expression) {
@@ -161,6 +217,23 @@ func (self *Builder) LeftJoin(table string, callback func(*Joiner)) *Builder {
 	return self
 }
 
+func (self *Builder) RightJoin(table string, callback func(*Joiner)) *Builder {
+	self.join(table, callback, "RIGHT")
+	return self
+}
+
+// FULL OUTER JOIN, в MySQL запрос вернет ошибку при выполнении
+func (self *Builder) FullJoin(table string, callback func(*Joiner)) *Builder {
+	self.join(table, callback, "FULL OUTER")
+	return self
+}
+
+// Декартово произведение, без условий
+func (self *Builder) CrossJoin(table string) *Builder {
+	self.components.Join = append(self.components.Join, joinComponent(*newJoiner(table, "CROSS")))
+	return self
+}
+
 func (self *Builder) join(table string, callback func(*Joiner), kind string) {
 	joiner := newJoiner(table, kind)
 	callback(joiner)
@@ -170,6 +243,50 @@ func (self *Builder) join(table string, callback func(*Joiner), kind string) {
 	}
 }
 
+// Объединение с подзапросом: INNER JOIN ( ... ) as alias ON ( ... )
+func (self *Builder) JoinSub(builder *Builder, alias string, callback func(*Joiner)) *Builder {
+	self.joinSub(builder, alias, callback, "INNER", false)
+	return self
+}
+
+func (self *Builder) LeftJoinSub(builder *Builder, alias string, callback func(*Joiner)) *Builder {
+	self.joinSub(builder, alias, callback, "LEFT", false)
+	return self
+}
+
+func (self *Builder) RightJoinSub(builder *Builder, alias string, callback func(*Joiner)) *Builder {
+	self.joinSub(builder, alias, callback, "RIGHT", false)
+	return self
+}
+
+// Объединение с LATERAL подзапросом, который может ссылаться на предыдущие таблицы
+// PostgreSQL и MySQL 8.0.14+, callback может быть nil, тогда условие ON TRUE
+func (self *Builder) JoinLateral(builder *Builder, alias string, callback func(*Joiner)) *Builder {
+	self.joinSub(builder, alias, callback, "INNER", true)
+	return self
+}
+
+func (self *Builder) LeftJoinLateral(builder *Builder, alias string, callback func(*Joiner)) *Builder {
+	self.joinSub(builder, alias, callback, "LEFT", true)
+	return self
+}
+
+func (self *Builder) joinSub(builder *Builder, alias string, callback func(*Joiner), kind string, lateral bool) {
+	joiner := newJoiner(alias, kind)
+	joiner.builder = builder
+	joiner.lateral = lateral
+	if callback != nil {
+		callback(joiner)
+	}
+	if len(joiner.conditions) == 0 && !lateral {
+		return
+	}
+	self.components.Join = append(self.components.Join, joinComponent(*joiner))
+	// Значения подзапроса идут перед значениями условий
+	self.bind("join", builder.rawData()...)
+	self.bind("join", joiner.bindings...)
+}
+
 func (self *Builder) Where(column string, operator string, value interface{}) *Builder {
 	self.where(column, operator, value, "AND")
 	return self
@@ -219,7 +336,7 @@ func (self *Builder) whereGroup(callback func(*Builder), boolean string) {
 			builder: builder,
 			boolean: boolean,
 		})
-		self.bind("where", builder.Data()...)
+		self.bind("where", builder.rawData()...)
 	}
 }
 
@@ -379,7 +496,7 @@ func (self *Builder) whereInSub(column string, builder *Builder, boolean string,
 		builder: builder,
 		boolean: boolean,
 	})
-	self.bind("where", builder.Data()...)
+	self.bind("where", builder.rawData()...)
 }
 
 func (self *Builder) whereInFun(column string, callback func(*Builder), boolean string, not bool) {
@@ -395,16 +512,12 @@ func (self *Builder) whereInFun(column string, callback func(*Builder), boolean
 		builder: builder,
 		boolean: boolean,
 	})
-	self.bind("where", builder.Data()...)
+	self.bind("where", builder.rawData()...)
 }
 
 func (self *Builder) GroupBy(p ...interface{}) *Builder {
 	self.components.Group = append(self.components.Group, p...)
-	for _, v := range p {
-		if exp, ok := v.(Expression); ok {
-			self.bind("group", exp.Data()...)
-		}
-	}
+	self.bind("group", clauseData(p...)...)
 	return self
 }
 
@@ -461,7 +574,7 @@ func (self *Builder) havingGroup(callback func(*Builder), boolean string) {
 			builder: builder,
 			boolean: boolean,
 		})
-		self.bind("having", builder.Data()...)
+		self.bind("having", builder.rawData()...)
 	}
 }
 
@@ -487,6 +600,43 @@ func (self *Builder) havingRaw(exp string, bindings []interface{}, boolean strin
 	self.bind("where", bindings...)
 }
 
+// Объединение результатов: UNION
+// Сортировка, Limit и Offset основного запроса применяются к объединенному результату
+func (self *Builder) Union(builder *Builder) *Builder {
+	self.union("UNION", builder)
+	return self
+}
+
+func (self *Builder) UnionAll(builder *Builder) *Builder {
+	self.union("UNION ALL", builder)
+	return self
+}
+
+func (self *Builder) Intersect(builder *Builder) *Builder {
+	self.union("INTERSECT", builder)
+	return self
+}
+
+func (self *Builder) Except(builder *Builder) *Builder {
+	self.union("EXCEPT", builder)
+	return self
+}
+
+func (self *Builder) union(kind string, builder *Builder) {
+	if self.kind != "" && self.kind != "select" {
+		panic("sqlx: " + strings.ToLower(kind) + " is allowed only for select")
+	}
+	data := builder.rawData()
+	if builder.kind != "" && builder.kind != "select" {
+		panic("sqlx: " + strings.ToLower(kind) + " is allowed only for select")
+	}
+	self.components.Union = append(self.components.Union, unionComponent{
+		kind:    kind,
+		builder: builder,
+	})
+	self.bind("union", data...)
+}
+
 func (self *Builder) OrderBy(column string, direction string) *Builder {
 	self.components.Order = append(self.components.Order, orderComponent{column, direction})
 	return self
@@ -564,16 +714,65 @@ func (self *Builder) Insert(data ...Data) *Builder {
 	return self
 }
 
+// Вставка с обновлением существующей записи при конфликте
+// data: sqlx.Data или []sqlx.Data
+// conflict: колонки уникального ключа, обязательны для PostgreSQL и SQLite, MySQL их не использует
+// update: []string - колонки, которые получат вставляемые значения,
+// sqlx.Data - колонки и значения, значением может быть sqlx.Raw
+func (self *Builder) Upsert(data interface{}, conflict []string, update interface{}) *Builder {
+	switch v := data.(type) {
+	case Data:
+		self.Insert(v)
+	case []Data:
+		self.Insert(v...)
+	default:
+		self.fail(errors.New("sqlx: upsert data must be sqlx.Data or []sqlx.Data"))
+		return self
+	}
+
+	if self.kind != "insert" {
+		return self
+	}
+
+	component := upsertComponent{
+		conflict: conflict,
+	}
+
+	switch v := update.(type) {
+	case []string:
+		component.columns = v
+	case Data:
+		component.set = v
+		self.bind("upsert", v.Values()...)
+	default:
+		self.fail(errors.New("sqlx: upsert update must be []string or sqlx.Data"))
+		return self
+	}
+
+	self.components.Upsert = []upsertComponent{component}
+
+	return self
+}
+
 func (self *Builder) OrIgnore() *Builder {
 	self.components.OrIgnore = []interface{}{true}
 	return self
 }
 
-func (self *Builder) ReturnId() *Builder {
-	self.components.ReturnId = []interface{}{true}
+// Колонки, возвращаемые запросами Insert, Update и Delete
+// PostgreSQL и SQLite 3.35+ используют RETURNING,
+// в MySQL эмулируется через LastInsertId только для вставки одной строки
+// и только для автоинкрементной колонки
+func (self *Builder) Returning(columns ...interface{}) *Builder {
+	self.components.Returning = append(self.components.Returning, columns...)
+	self.bind("returning", clauseData(columns...)...)
 	return self
 }
 
+func (self *Builder) ReturnId() *Builder {
+	return self.Returning("id")
+}
+
 func (self *Builder) Count(column interface{}, alias string) *Builder {
 	self.aggregate("count", column, alias)
 	return self
@@ -611,24 +810,74 @@ func (self *Builder) aggregate(function string, column interface{}, alias string
 	})
 }
 
+// Строка запроса в диалекте драйвера по умолчанию
+// Ошибки построения возвращает выполнение запроса через Query
 func (self *Builder) Sql() string {
-	if driver == nil {
+	query, _ := self.compile("")
+	return query
+}
+
+// Строка запроса и данные для плейсхолдеров в указанном диалекте
+func (self *Builder) ToSQL(dialect string) (string, []interface{}) {
+	if dialect == "" {
+		panic("sqlx: dialect is not defined")
+	}
+	query, _ := self.compile(dialect)
+	return query, self.data(dialect)
+}
+
+// Компиляция в указанном диалекте, пустая строка - драйвер по умолчанию
+// Ошибка - первая ошибка построения строителя или диалекта
+func (self *Builder) compile(dialect string) (string, error) {
+	glammar := driver
+	if dialect != "" {
+		glammar = lookupDriver(dialect)
+	}
+	if glammar == nil {
 		panic("sqlx: driver is not defined")
 	}
-	if self.kind == "" {
-		self.Select("*")
+	g := glammar()
+	query := g.compile(self)
+	return query, g.failure()
+}
+
+// Запоминает первую ошибку построения
+func (self *Builder) fail(err error) {
+	if self.err == nil {
+		self.err = err
 	}
-	return driver().compile(self)
 }
 
+// Данные для плейсхолдеров, строитель без типа запроса считается выборкой
 func (self *Builder) Data() []interface{} {
-	if self.kind == "" {
-		self.Select("*")
+	return self.data("")
+}
+
+// Данные в указанном диалекте, пустая строка - драйвер по умолчанию
+func (self *Builder) data(dialect string) []interface{} {
+	bindings := make([]interface{}, 0)
+	for _, v := range self.rawData() {
+		if c, ok := v.(cursorBinding); ok {
+			bindings = append(bindings, c.data(dialect)...)
+			continue
+		}
+		bindings = append(bindings, v)
+	}
+	return bindings
+}
+
+// Данные с нераскрытыми условиями курсора
+// Вложенные строители передают их родителю, который раскрывает их по своему диалекту
+func (self *Builder) rawData() []interface{} {
+	kind := self.kind
+	if kind == "" {
+		kind = "select"
 	}
 	bindings := make([]interface{}, 0)
-	for _, k := range bindingsMap[self.kind] {
-		for _, v := range self.bindings[k] {
-			bindings = append(bindings, v)
+	for _, k := range bindingsMap[kind] {
+		bindings = append(bindings, self.bindings[k]...)
+		if k == "where" && self.cursor != nil && self.cursor.where != nil {
+			bindings = append(bindings, *self.cursor.where)
 		}
 	}
 	return bindings
@@ -636,9 +885,12 @@ func (self *Builder) Data() []interface{} {
 
 func (self *Builder) bind(k string, b ...interface{}) {
 	for _, v := range b {
-		if exp, ok := v.(Expression); ok {
-			self.bindings[k] = append(self.bindings[k], exp.Data()...)
-		} else {
+		switch x := v.(type) {
+		case Expression:
+			self.bindings[k] = append(self.bindings[k], x.Data()...)
+		case clause:
+			self.bindings[k] = append(self.bindings[k], x.Data()...)
+		default:
 			self.bindings[k] = append(self.bindings[k], v)
 		}
 	}
@@ -9,17 +9,30 @@ import (
 
 type ChunkFunk interface{}
 
+var errChunkSize = errors.New("sqlx: chunk size must be at least 1")
+
 type Chunker struct {
-	rows *sql.Rows
+	rows   *sql.Rows
+	strict bool
 }
 
 func NewChunker(r *sql.Rows) *Chunker {
-	return &Chunker{r}
+	return &Chunker{rows: r}
+}
+
+// Строгий режим: колонка без соответствующего поля структуры является ошибкой
+func (self *Chunker) Strict(enable bool) *Chunker {
+	self.strict = enable
+	return self
 }
 
 func (self *Chunker) Chunk(n int, f ChunkFunk) error {
 	defer self.rows.Close()
 
+	if n < 1 {
+		return errChunkSize
+	}
+
 	funcValue := reflect.ValueOf(f)
 	funcType := funcValue.Type()
 
@@ -50,45 +63,30 @@ func (self *Chunker) Chunk(n int, f ChunkFunk) error {
 		}
 	}
 
+	sliceValue := reflect.Indirect(reflect.New(sliceType))
+	sliceElem := sliceType.Elem()
+
+	if sliceElem.Kind() != reflect.Struct {
+		return errors.New("sqlx: two parameter must be func([]Struct)")
+	}
+
 	columns, err := self.rows.Columns()
 	if err != nil {
 		return fmt.Errorf("sqlx: %s", err)
 	}
 
-	for k, v := range columns {
-		columns[k] = toCamel(v)
-	}
-
-	sliceValuePrt := reflect.New(sliceType)
-	sliceValue := reflect.Indirect(sliceValuePrt)
-
-	sliceElem := sliceType.Elem()
-	structValuePrt := reflect.New(sliceElem)
-	structValue := reflect.Indirect(structValuePrt)
-
-	if structValue.Kind() != reflect.Struct {
-		return errors.New("sqlx: two parameter must be func([]Struct)")
+	indexes, err := structFieldMap(sliceElem).indexes(columns, self.strict)
+	if err != nil {
+		return err
 	}
 
 	var found bool = false
 	var interrupt bool = false
 	var i int = 1
 	for self.rows.Next() {
-		structValuePrt := reflect.New(sliceElem)
-		structValue := reflect.Indirect(structValuePrt)
-
-		fields := deepStructFields(structValuePrt.Interface())
-		values := make([]interface{}, len(columns))
-
-		for i, column := range columns {
-			if field, ok := fields[column]; ok {
-				values[i] = field.Addr().Interface()
-			} else {
-				values[i] = &sql.NullString{}
-			}
-		}
+		structValue := reflect.Indirect(reflect.New(sliceElem))
 
-		if err := self.rows.Scan(values...); err != nil {
+		if err := self.rows.Scan(pointers(structValue, indexes)...); err != nil {
 			return fmt.Errorf("sqlx: %s", err)
 		}
 
@@ -0,0 +1,199 @@
+package sqlx
+
+// Глубокая копия строителя
+// Компоненты, данные и вложенные запросы копируются,
+// поэтому изменение копии не затрагивает исходный строитель
+//
+//	base := sqlx.Table("users").Where("active", "=", true)
+//	count := base.Clone().ClearSelect().Count("*", "count")
+//	list := base.Clone().OrderBy("id", "desc").Limit(20)
+func (self *Builder) Clone() *Builder {
+	if self == nil {
+		return nil
+	}
+
+	c := self.components
+	builder := &Builder{
+		kind:  self.kind,
+		table: self.table,
+		components: &components{
+			With:      cloneSlice(c.With, cloneWith),
+			Distinct:  cloneValues(c.Distinct),
+			Aggregate: cloneSlice(c.Aggregate, nil),
+			Select:    cloneValues(c.Select),
+			Insert:    cloneValues(c.Insert),
+			Update:    cloneValues(c.Update),
+			Delete:    cloneValues(c.Delete),
+			From:      cloneSlice(c.From, cloneFrom),
+			Join:      cloneSlice(c.Join, cloneJoin),
+			Into:      cloneValues(c.Into),
+			Columns:   cloneValues(c.Columns),
+			Values:    cloneSlice(c.Values, cloneValue),
+			OrIgnore:  cloneValues(c.OrIgnore),
+			Upsert:    cloneSlice(c.Upsert, cloneUpsert),
+			Returning: cloneValues(c.Returning),
+			Set:       cloneSlice(c.Set, cloneSet),
+			Where:     cloneSlice(c.Where, cloneWhere),
+			Group:     cloneValues(c.Group),
+			Having:    cloneSlice(c.Having, cloneHaving),
+			Union:     cloneSlice(c.Union, cloneUnion),
+			Order:     cloneSlice(c.Order, nil),
+			Limit:     cloneValues(c.Limit),
+			Offset:    cloneValues(c.Offset),
+		},
+		bindings: make(map[string][]interface{}, len(self.bindings)),
+		structs:  cloneSlice(self.structs, nil),
+		primary:  self.primary,
+		err:      self.err,
+	}
+
+	for k, v := range self.bindings {
+		builder.bindings[k] = cloneSlice(v, nil)
+	}
+
+	if self.cursor != nil {
+		cursor := *self.cursor
+		builder.cursor = &cursor
+	}
+
+	return builder
+}
+
+// Применение области запроса, повторно используемого набора условий
+//
+//	active := func(b *sqlx.Builder) { b.Where("active", "=", true).WhereNull("deleted_at") }
+//	users := sqlx.Table("users").Scope(active).OrderBy("id", "asc")
+func (self *Builder) Scope(scopes ...func(*Builder)) *Builder {
+	for _, scope := range scopes {
+		scope(self)
+	}
+	return self
+}
+
+// Сброс выбираемых колонок и агрегатных функций, по умолчанию выбирается *
+func (self *Builder) ClearSelect() *Builder {
+	self.components.Select = nil
+	self.components.Aggregate = nil
+	self.bindings["select"] = []interface{}{}
+	if self.kind == "select" {
+		self.kind = ""
+	}
+	return self
+}
+
+// Сброс сортировки
+func (self *Builder) ClearOrder() *Builder {
+	self.components.Order = nil
+	return self
+}
+
+// Сброс лимита
+func (self *Builder) ClearLimit() *Builder {
+	self.components.Limit = nil
+	self.bindings["limit"] = []interface{}{}
+	return self
+}
+
+// Сброс смещения
+func (self *Builder) ClearOffset() *Builder {
+	self.components.Offset = nil
+	self.bindings["offset"] = []interface{}{}
+	return self
+}
+
+// Копия среза, clone копирует каждый элемент
+func cloneSlice[T any](list []T, clone func(T) T) []T {
+	if list == nil {
+		return nil
+	}
+	result := make([]T, len(list))
+	for k, v := range list {
+		if clone != nil {
+			v = clone(v)
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// Копия значений, подзапросы в Select копируются
+func cloneValues(list []interface{}) []interface{} {
+	return cloneSlice(list, func(v interface{}) interface{} {
+		if sub, ok := v.(subSelect); ok {
+			return subSelect{sub.builder.Clone(), sub.alias}
+		}
+		return v
+	})
+}
+
+func cloneData(data Data) Data {
+	if data == nil {
+		return nil
+	}
+	result := make(Data, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result
+}
+
+func cloneWith(w withComponent) withComponent {
+	w.columns = cloneSlice(w.columns, nil)
+	w.builder, w.recursive = w.builder.Clone(), w.recursive.Clone()
+	return w
+}
+
+func cloneFrom(f fromComponent) fromComponent {
+	f.builder = f.builder.Clone()
+	return f
+}
+
+func cloneJoin(j joinComponent) joinComponent {
+	return joinComponent(*cloneJoiner((*Joiner)(&j)))
+}
+
+func cloneJoiner(j *Joiner) *Joiner {
+	if j == nil {
+		return nil
+	}
+	joiner := *j
+	joiner.builder = j.builder.Clone()
+	joiner.bindings = cloneSlice(j.bindings, nil)
+	joiner.conditions = cloneSlice(j.conditions, func(c joinCondition) joinCondition {
+		c.list = cloneSlice(c.list, nil)
+		c.joiner = cloneJoiner(c.joiner)
+		return c
+	})
+	return &joiner
+}
+
+func cloneValue(v valueComponent) valueComponent {
+	return cloneSlice(v, nil)
+}
+
+func cloneUpsert(u upsertComponent) upsertComponent {
+	u.conflict = cloneSlice(u.conflict, nil)
+	u.columns = cloneSlice(u.columns, nil)
+	u.set = cloneData(u.set)
+	return u
+}
+
+func cloneSet(s setComponent) setComponent {
+	return setComponent(cloneData(Data(s)))
+}
+
+func cloneWhere(w whereComponent) whereComponent {
+	w.list = cloneSlice(w.list, nil)
+	w.builder = w.builder.Clone()
+	return w
+}
+
+func cloneHaving(h havingComponent) havingComponent {
+	h.builder = h.builder.Clone()
+	return h
+}
+
+func cloneUnion(u unionComponent) unionComponent {
+	u.builder = u.builder.Clone()
+	return u
+}
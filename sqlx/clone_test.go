@@ -0,0 +1,78 @@
+package sqlx
+
+import (
+	"testing"
+)
+
+func TestBuilderClone(t *testing.T) {
+	base := Table("users").
+		Select("id", "name").
+		JoinSub(Table("posts").Select("user_id").Where("draft", "=", false), "p", func(j *Joiner) {
+			j.On("p.user_id", "=", "users.id")
+		}).
+		WhereGroup(func(b *Builder) {
+			b.Where("age", ">", 18).OrWhere("admin", "=", true)
+		}).
+		Union(Table("guests").Select("id", "name"))
+
+	clone := base.Clone()
+	clone.Where("active", "=", true).OrderBy("id", "desc").Limit(10)
+	base.Where("name", "=", "Jack").OrderBy("name", "asc")
+
+	expect := `( SELECT "id", "name" FROM "users" INNER JOIN ( SELECT "user_id" FROM "posts" WHERE "draft" = $1 ) as "p" ON ( "p"."user_id" = "users"."id" ) WHERE ( "age" > $2 OR "admin" = $3 ) AND "name" = $4 ) UNION ( SELECT "id", "name" FROM "guests" ) ORDER BY "name" asc`
+	if result := base.Sql(); result != expect || !DataEqual(base.Data(), []interface{}{false, 18, true, "Jack"}) {
+		t.Errorf("Expect result to equal in func TestBuilderClone.\nResult: %s %v\nExpect: %s", result, base.Data(), expect)
+	}
+
+	expect = `( SELECT "id", "name" FROM "users" INNER JOIN ( SELECT "user_id" FROM "posts" WHERE "draft" = $1 ) as "p" ON ( "p"."user_id" = "users"."id" ) WHERE ( "age" > $2 OR "admin" = $3 ) AND "active" = $4 ) UNION ( SELECT "id", "name" FROM "guests" ) ORDER BY "id" desc LIMIT $5`
+	if result := clone.Sql(); result != expect || !DataEqual(clone.Data(), []interface{}{false, 18, true, true, 10}) {
+		t.Errorf("Expect result to equal in func TestBuilderClone.\nResult: %s %v\nExpect: %s", result, clone.Data(), expect)
+	}
+}
+
+func TestBuilderSqlImmutable(t *testing.T) {
+	builder := Table("users")
+	builder.Sql()
+	builder.Data()
+
+	expect := `SELECT "id" FROM "users"`
+	if result := builder.Select("id").Sql(); result != expect {
+		t.Errorf("Expect result to equal in func TestBuilderSqlImmutable.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestBuilderScope(t *testing.T) {
+	active := func(b *Builder) {
+		b.Where("active", "=", true).WhereNull("deleted_at")
+	}
+	adults := func(b *Builder) {
+		b.Where("age", ">=", 18)
+	}
+
+	expect := `SELECT * FROM "users" WHERE "active" = $1 AND "deleted_at" IS NULL AND "age" >= $2`
+	builder := Table("users").Scope(active, adults)
+	if result := builder.Sql(); result != expect || !DataEqual(builder.Data(), []interface{}{true, 18}) {
+		t.Errorf("Expect result to equal in func TestBuilderScope.\nResult: %s %v\nExpect: %s", result, builder.Data(), expect)
+	}
+}
+
+func TestBuilderClear(t *testing.T) {
+	builder := Table("users").
+		SelectRaw("COALESCE(name, ?) as name", "guest").
+		Count("*", "count").
+		Where("age", ">", 18).
+		OrderBy("id", "desc").
+		Limit(10).
+		Offset(20)
+
+	expect := `SELECT * FROM "users" WHERE "age" > $1`
+	builder.ClearSelect().ClearOrder().ClearLimit().ClearOffset()
+	if result := builder.Sql(); result != expect || !DataEqual(builder.Data(), []interface{}{18}) {
+		t.Errorf("Expect result to equal in func TestBuilderClear.\nResult: %s %v\nExpect: %s", result, builder.Data(), expect)
+	}
+
+	expect = `SELECT "id" FROM "users" WHERE "age" > $1 LIMIT $2`
+	if result := builder.Select("id").Limit(5).Sql(); result != expect || !DataEqual(builder.Data(), []interface{}{18, 5}) {
+		t.Errorf("Expect result to equal in func TestBuilderClear.\nResult: %s %v\nExpect: %s", result, builder.Data(), expect)
+	}
+}
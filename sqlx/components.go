@@ -2,6 +2,8 @@ package sqlx
 
 // Компоненты запроса
 type components struct {
+	With      []withComponent
+	Distinct  []interface{}
 	Aggregate []aggregateComponent
 	Select    []interface{}
 	Insert    []interface{}
@@ -13,11 +15,13 @@ type components struct {
 	Columns   []interface{}
 	Values    []valueComponent
 	OrIgnore  []interface{}
-	ReturnId  []interface{}
+	Upsert    []upsertComponent
+	Returning []interface{}
 	Set       []setComponent
 	Where     []whereComponent
 	Group     []interface{}
 	Having    []havingComponent
+	Union     []unionComponent
 	Order     []orderComponent
 	Limit     []interface{}
 	Offset    []interface{}
@@ -27,6 +31,13 @@ func newComponents() *components {
 	return &components{}
 }
 
+type withComponent struct {
+	name      string
+	columns   []string
+	builder   *Builder
+	recursive *Builder
+}
+
 type aggregateComponent struct {
 	function string
 	column   interface{}
@@ -64,6 +75,17 @@ type havingComponent struct {
 	builder  *Builder
 }
 
+type upsertComponent struct {
+	conflict []string
+	columns  []string
+	set      Data
+}
+
+type unionComponent struct {
+	kind    string
+	builder *Builder
+}
+
 type orderComponent struct {
 	column    string
 	direction string
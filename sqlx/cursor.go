@@ -0,0 +1,445 @@
+package sqlx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	sqldriver "database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Курсор поврежден, подписан другим ключом или выдан для другой сортировки
+var ErrInvalidCursor = errors.New("sqlx: invalid cursor")
+
+var cursorSecret struct {
+	sync.RWMutex
+	key []byte
+}
+
+// Ключ подписи курсоров, должен быть задан до использования CursorPaginate
+// Без ключа ScanCursor возвращает ошибку
+func CursorSecret(secret []byte) {
+	cursorSecret.Lock()
+	defer cursorSecret.Unlock()
+	cursorSecret.key = append([]byte(nil), secret...)
+}
+
+// Страница результата с курсорами соседних страниц
+// Пустой курсор означает, что страницы нет
+type CursorPage struct {
+	Next string
+	Prev string
+}
+
+func (self *CursorPage) HasNext() bool {
+	return self.Next != ""
+}
+
+func (self *CursorPage) HasPrev() bool {
+	return self.Prev != ""
+}
+
+// Параметры курсорной пагинации запроса
+type cursorState struct {
+	order    []orderComponent
+	size     int
+	backward bool
+	started  bool
+	where    *cursorBinding
+	err      error
+}
+
+// Курсорная (keyset) пагинация
+//
+// order - колонки сортировки с направлением: "created_at desc", "id asc".
+// Последняя колонка должна быть уникальной, колонки не должны содержать NULL.
+// cursor - значение CursorPage.Next или CursorPage.Prev предыдущей страницы, пустой для первой.
+// Условие строится через сравнение строк ( a, b ) > ( ?, ? ) при одинаковом направлении
+// и через раскрытые сравнения a > ? OR ( a = ? AND b < ? ) при смешанном и в MySQL.
+// Ошибка разбора курсора возвращается из Query.ScanCursor.
+//
+//	builder := sqlx.Table("posts").CursorPaginate([]string{"created_at desc", "id desc"}, cursor, 20)
+//	posts := []Post{}
+//	page, err := dbx.Query(builder).ScanCursor(&posts)
+func (self *Builder) CursorPaginate(order []string, cursor string, size int) *Builder {
+	if len(order) == 0 || size < 1 {
+		panic("sqlx: cursor paginate requires order columns and positive size")
+	}
+
+	state := &cursorState{size: size, started: cursor != ""}
+	for _, v := range order {
+		segments := strings.Fields(v)
+		direction := "asc"
+		if len(segments) > 1 {
+			direction = strings.ToLower(segments[1])
+		}
+		if len(segments) == 0 || len(segments) > 2 || direction != "asc" && direction != "desc" {
+			panic("sqlx: invalid cursor order '" + v + "'")
+		}
+		state.order = append(state.order, orderComponent{segments[0], direction})
+	}
+
+	var values []interface{}
+	if cursor != "" {
+		values, state.backward, state.err = decodeCursor(cursor, state.order)
+	}
+
+	for _, o := range state.order {
+		direction := o.direction
+		if state.backward {
+			direction = reverseDirection(direction)
+		}
+		self.OrderBy(o.column, direction)
+	}
+
+	if values != nil {
+		state.where = cursorWhere(state.order, values, state.backward)
+	}
+
+	self.Limit(size + 1)
+	self.cursor = state
+
+	return self
+}
+
+// Условие для строк после значений values в порядке сортировки
+// Хранится отдельно от условий Where и добавляется к ним при компиляции,
+// поэтому условия, добавленные после CursorPaginate, не обходят курсор
+func cursorWhere(order []orderComponent, values []interface{}, backward bool) *cursorBinding {
+	columns := make([]orderComponent, len(order))
+	for k, o := range order {
+		columns[k] = o
+		if backward {
+			columns[k].direction = reverseDirection(o.direction)
+		}
+	}
+	return &cursorBinding{columns, values}
+}
+
+// Условия Where вместе с условием курсора
+// Условия через OR группируются, чтобы курсор ограничивал их все
+func (self *Builder) whereComponents() []whereComponent {
+	if self.cursor == nil || self.cursor.where == nil {
+		return self.components.Where
+	}
+
+	where := self.components.Where
+	for _, w := range where {
+		if w.boolean == "OR" {
+			group := Table(self.table)
+			group.components.Where = where
+			where = []whereComponent{{kind: "group", builder: group}}
+			break
+		}
+	}
+
+	boolean := "AND"
+	if len(where) == 0 {
+		boolean = ""
+	}
+	return append(where[:len(where):len(where)], whereComponent{
+		kind:    "cursor",
+		column:  self.cursor.where.order,
+		list:    self.cursor.where.values,
+		boolean: boolean,
+	})
+}
+
+// Данные условия курсора, раскрываются по диалекту в Builder.data
+// Набор данных зависит от формы условия, она известна только при компиляции
+type cursorBinding struct {
+	order  []orderComponent
+	values []interface{}
+}
+
+func (self cursorBinding) data(dialect string) []interface{} {
+	glammar := driver
+	if dialect != "" {
+		glammar = lookupDriver(dialect)
+	}
+	expand := !uniformDirection(self.order)
+	if glammar != nil {
+		expand = glammar().expandCursor(self.order)
+	}
+	if !expand {
+		return self.values
+	}
+	data := make([]interface{}, 0, len(self.values)*(len(self.values)+1)/2)
+	for k := range self.values {
+		data = append(data, self.values[:k+1]...)
+	}
+	return data
+}
+
+func uniformDirection(order []orderComponent) bool {
+	for _, o := range order {
+		if o.direction != order[0].direction {
+			return false
+		}
+	}
+	return true
+}
+
+func reverseDirection(direction string) string {
+	if direction == "desc" {
+		return "asc"
+	}
+	return "desc"
+}
+
+// Сканирование страницы в срез dest и курсоры соседних страниц
+func (self *Query) ScanCursor(dest interface{}) (*CursorPage, error) {
+	if self.cursor == nil {
+		return nil, errors.New("sqlx: query is built without CursorPaginate")
+	}
+	if self.cursor.err != nil {
+		return nil, self.cursor.err
+	}
+
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return nil, errors.New("sqlx: destination must be a pointer to slice")
+	}
+	slice = slice.Elem()
+
+	// Срез заполняется заново, пустая страница не ошибка и курсоров у нее нет
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+	if err := self.Scan(dest); err == ErrNoRows {
+		return &CursorPage{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := self.cursor
+	more := slice.Len() > state.size
+	if more {
+		slice.Set(slice.Slice(0, state.size))
+	}
+	if state.backward {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	page := &CursorPage{}
+	var err error
+
+	// Назад страница выбирается от курсора, поэтому следующая за ней всегда есть
+	if more || state.backward {
+		if page.Next, err = encodeCursor(slice.Index(slice.Len()-1), state.order, false); err != nil {
+			return nil, err
+		}
+	}
+	if state.backward && more || !state.backward && state.started {
+		if page.Prev, err = encodeCursor(slice.Index(0), state.order, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+type cursorPayload struct {
+	Backward bool             `json:"b,omitempty"`
+	Values   []cursorValueRaw `json:"v"`
+}
+
+// Значение с типом, чтобы целые числа и время не теряли точность
+type cursorValueRaw struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v,omitempty"`
+}
+
+func encodeCursor(row reflect.Value, order []orderComponent, backward bool) (string, error) {
+	payload := cursorPayload{Backward: backward}
+	for _, o := range order {
+		v, err := cursorColumnValue(row, o.column)
+		if err != nil {
+			return "", err
+		}
+		raw, err := encodeCursorValue(v)
+		if err != nil {
+			return "", fmt.Errorf("sqlx: cursor column %s: %s", o.column, err)
+		}
+		payload.Values = append(payload.Values, raw)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("sqlx: %s", err)
+	}
+
+	sign, err := cursorSign(data, order)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(sign), nil
+}
+
+func decodeCursor(cursor string, order []orderComponent) ([]interface{}, bool, error) {
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 {
+		return nil, false, ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, false, ErrInvalidCursor
+	}
+	expect, err := cursorSign(data, order)
+	if err != nil {
+		return nil, false, err
+	}
+	sign, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(sign, expect) {
+		return nil, false, ErrInvalidCursor
+	}
+
+	payload := cursorPayload{}
+	if err := json.Unmarshal(data, &payload); err != nil || len(payload.Values) != len(order) {
+		return nil, false, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(payload.Values))
+	for k, raw := range payload.Values {
+		if values[k], err = decodeCursorValue(raw); err != nil {
+			return nil, false, ErrInvalidCursor
+		}
+	}
+
+	return values, payload.Backward, nil
+}
+
+// Подпись данных курсора вместе с колонками сортировки
+func cursorSign(data []byte, order []orderComponent) ([]byte, error) {
+	cursorSecret.RLock()
+	defer cursorSecret.RUnlock()
+	if len(cursorSecret.key) == 0 {
+		return nil, errors.New("sqlx: cursor secret is not defined")
+	}
+	mac := hmac.New(sha256.New, cursorSecret.key)
+	mac.Write(data)
+	for _, o := range order {
+		mac.Write([]byte("\x00" + o.column + " " + o.direction))
+	}
+	return mac.Sum(nil), nil
+}
+
+// Значение колонки сортировки из структуры или карты
+// Для колонок вида table.column используется имя без таблицы
+func cursorColumnValue(row reflect.Value, column string) (interface{}, error) {
+	name := column
+	if k := strings.LastIndex(column, "."); k >= 0 {
+		name = column[k+1:]
+	}
+
+	row = reflect.Indirect(row)
+	switch row.Kind() {
+	case reflect.Struct:
+		if f, ok := structFieldMap(row.Type()).lookup(name); ok {
+			return row.FieldByIndex(f.index).Interface(), nil
+		}
+	case reflect.Map:
+		if row.Type().Key().Kind() == reflect.String {
+			if v := row.MapIndex(reflect.ValueOf(name).Convert(row.Type().Key())); v.IsValid() {
+				return v.Interface(), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("sqlx: cursor column %s not found in %s", column, row.Type())
+}
+
+func encodeCursorValue(v interface{}) (cursorValueRaw, error) {
+	if valuer, ok := v.(sqldriver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return cursorValueRaw{}, err
+		}
+		v = value
+	}
+
+	var typ string
+	var value interface{}
+
+	switch x := v.(type) {
+	case nil:
+		return cursorValueRaw{Type: "n"}, nil
+	case time.Time:
+		typ, value = "t", x.Format(time.RFC3339Nano)
+	case []byte:
+		typ, value = "x", x
+	default:
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			typ, value = "i", strconv.FormatInt(rv.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			typ, value = "u", strconv.FormatUint(rv.Uint(), 10)
+		case reflect.Float32, reflect.Float64:
+			typ, value = "f", rv.Float()
+		case reflect.Bool:
+			typ, value = "b", rv.Bool()
+		case reflect.String:
+			typ, value = "s", rv.String()
+		default:
+			return cursorValueRaw{}, fmt.Errorf("unsupported type %T", v)
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return cursorValueRaw{}, err
+	}
+	return cursorValueRaw{Type: typ, Value: data}, nil
+}
+
+func decodeCursorValue(raw cursorValueRaw) (interface{}, error) {
+	switch raw.Type {
+	case "n":
+		return nil, nil
+	case "t":
+		var s string
+		if err := json.Unmarshal(raw.Value, &s); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case "x":
+		var b []byte
+		err := json.Unmarshal(raw.Value, &b)
+		return b, err
+	case "i":
+		var s string
+		if err := json.Unmarshal(raw.Value, &s); err != nil {
+			return nil, err
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case "u":
+		var s string
+		if err := json.Unmarshal(raw.Value, &s); err != nil {
+			return nil, err
+		}
+		return strconv.ParseUint(s, 10, 64)
+	case "f":
+		var f float64
+		err := json.Unmarshal(raw.Value, &f)
+		return f, err
+	case "b":
+		var b bool
+		err := json.Unmarshal(raw.Value, &b)
+		return b, err
+	case "s":
+		var s string
+		err := json.Unmarshal(raw.Value, &s)
+		return s, err
+	}
+	return nil, fmt.Errorf("unknown type %s", raw.Type)
+}
@@ -0,0 +1,147 @@
+package sqlx
+
+import (
+	sqldriver "database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type cursorPost struct {
+	Id    int64
+	Score int64
+}
+
+func TestSqlCursorPaginate(t *testing.T) {
+	CursorSecret([]byte("secret"))
+
+	expect := `SELECT * FROM "posts" WHERE "active" = $1 ORDER BY "score" desc, "id" desc LIMIT $2`
+	builder := Table("posts").Where("active", "=", true).CursorPaginate([]string{"score desc", "id desc"}, "", 10)
+	if result := builder.Sql(); result != expect || !DataEqual(builder.Data(), []interface{}{true, 11}) {
+		t.Errorf("Expect result to equal in func TestSqlCursorPaginate.\nResult: %s %v\nExpect: %s", result, builder.Data(), expect)
+	}
+
+	order := []orderComponent{{"score", "desc"}, {"id", "desc"}}
+	cursor, _ := encodeCursor(reflect.ValueOf(cursorPost{Id: 7, Score: 50}), order, false)
+
+	expect = `SELECT * FROM "posts" WHERE ( "active" = $1 OR "draft" = $2 ) AND ( "score", "id" ) < ( $3, $4 ) ORDER BY "score" desc, "id" desc LIMIT $5`
+	builder = Table("posts").Where("active", "=", true).OrWhere("draft", "=", false).CursorPaginate([]string{"score desc", "id desc"}, cursor, 10)
+	if result := builder.Sql(); result != expect || !DataEqual(builder.Data(), []interface{}{true, false, int64(50), int64(7), 11}) {
+		t.Errorf("Expect result to equal in func TestSqlCursorPaginate.\nResult: %s %v\nExpect: %s", result, builder.Data(), expect)
+	}
+
+	builder = Table("posts").Where("active", "=", true).CursorPaginate([]string{"score desc", "id desc"}, cursor, 10).OrWhere("draft", "=", false)
+	if result := builder.Sql(); result != expect || !DataEqual(builder.Data(), []interface{}{true, false, int64(50), int64(7), 11}) {
+		t.Errorf("Expect result to equal in func TestSqlCursorPaginate.\nResult: %s %v\nExpect: %s", result, builder.Data(), expect)
+	}
+
+	order = []orderComponent{{"score", "desc"}, {"id", "asc"}}
+	cursor, _ = encodeCursor(reflect.ValueOf(cursorPost{Id: 7, Score: 50}), order, true)
+
+	expect = "SELECT * FROM `posts` WHERE ( ( `score` > ? ) OR ( `score` = ? AND `id` < ? ) ) ORDER BY `score` asc, `id` desc LIMIT ?"
+	result, data := Table("posts").CursorPaginate([]string{"score desc", "id"}, cursor, 10).ToSQL("mysql")
+	if result != expect || !DataEqual(data, []interface{}{int64(50), int64(50), int64(7), 11}) {
+		t.Errorf("Expect result to equal in func TestSqlCursorPaginate.\nResult: %s %v\nExpect: %s", result, data, expect)
+	}
+
+	order = []orderComponent{{"score", "desc"}, {"id", "desc"}}
+	cursor, _ = encodeCursor(reflect.ValueOf(cursorPost{Id: 7, Score: 50}), order, false)
+
+	expect = "SELECT * FROM `posts` WHERE ( ( `score` < ? ) OR ( `score` = ? AND `id` < ? ) ) ORDER BY `score` desc, `id` desc LIMIT ?"
+	result, data = Table("posts").CursorPaginate([]string{"score desc", "id desc"}, cursor, 10).ToSQL("mysql")
+	if result != expect || !DataEqual(data, []interface{}{int64(50), int64(50), int64(7), 11}) {
+		t.Errorf("Expect result to equal in func TestSqlCursorPaginate.\nResult: %s %v\nExpect: %s", result, data, expect)
+	}
+
+	builder = Table("posts").Where("id", "=", 1).Union(Table("posts").CursorPaginate([]string{"score desc", "id desc"}, cursor, 10))
+	if _, data = builder.ToSQL("mysql"); !DataEqual(data, []interface{}{1, int64(50), int64(50), int64(7), 11}) {
+		t.Errorf("Unexpected mysql cursor data of union in func TestSqlCursorPaginate: %v", data)
+	}
+
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+	DataBase(db, "mysql").Query(Table("posts").CursorPaginate([]string{"score desc", "id desc"}, cursor, 10)).Exec()
+	if args := fake.calls[0].args; !DataEqual(args, []interface{}{int64(50), int64(50), int64(7), int64(11)}) {
+		t.Errorf("Unexpected mysql cursor data in func TestSqlCursorPaginate: %v", args)
+	}
+}
+
+func TestScanCursor(t *testing.T) {
+	CursorSecret([]byte("secret"))
+
+	db, fake := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		rows := [][]sqldriver.Value{{int64(1), int64(30)}, {int64(2), int64(20)}, {int64(3), int64(20)}}
+		if strings.Contains(query, `"score" asc`) {
+			rows = [][]sqldriver.Value{{int64(1), int64(30)}}
+		}
+		return fakeResponse{columns: []string{"id", "score"}, rows: rows}
+	})
+	defer db.Close()
+	dbx := DataBase(db)
+	order := []string{"score desc", "id asc"}
+
+	posts := []cursorPost{}
+	page, err := dbx.Query(Table("posts").CursorPaginate(order, "", 2)).ScanCursor(&posts)
+	if err != nil || len(posts) != 2 || !page.HasNext() || page.HasPrev() {
+		t.Fatalf("Unexpected first page in func TestScanCursor: %v, %+v, %v", posts, page, err)
+	}
+
+	next := []map[string]interface{}{}
+	page, err = dbx.Query(Table("posts").CursorPaginate(order, page.Next, 2)).ScanCursor(&next)
+	if err != nil || !page.HasNext() || !page.HasPrev() {
+		t.Fatalf("Unexpected next page in func TestScanCursor: %v, %+v, %v", next, page, err)
+	}
+	if args := fake.calls[1].args; !DataEqual(args, []interface{}{int64(20), int64(20), int64(2), int64(3)}) {
+		t.Errorf("Unexpected cursor data in func TestScanCursor: %v", args)
+	}
+
+	prev := []*cursorPost{}
+	page, err = dbx.Query(Table("posts").CursorPaginate(order, page.Prev, 2)).ScanCursor(&prev)
+	if err != nil || len(prev) != 1 || prev[0].Id != 1 || !page.HasNext() || page.HasPrev() {
+		t.Errorf("Unexpected prev page in func TestScanCursor: %v, %+v, %v", prev, page, err)
+	}
+
+	if _, err := dbx.Query(Table("posts").CursorPaginate([]string{"id"}, page.Next, 2)).ScanCursor(&posts); err != ErrInvalidCursor {
+		t.Errorf("Expect invalid cursor for another order in func TestScanCursor: %v", err)
+	}
+	if _, err := dbx.Query(Table("posts").CursorPaginate(order, "x"+page.Next, 2)).ScanCursor(&posts); err != ErrInvalidCursor {
+		t.Errorf("Expect invalid cursor for tampered token in func TestScanCursor: %v", err)
+	}
+}
+
+func TestScanCursorEmpty(t *testing.T) {
+	CursorSecret([]byte("secret"))
+
+	db, _ := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		return fakeResponse{columns: []string{"id", "score"}}
+	})
+	defer db.Close()
+
+	cursor, _ := encodeCursor(reflect.ValueOf(cursorPost{Id: 7, Score: 50}), []orderComponent{{"id", "asc"}}, false)
+	posts := []cursorPost{{Id: 1}}
+	page, err := DataBase(db).Query(Table("posts").CursorPaginate([]string{"id"}, cursor, 2)).ScanCursor(&posts)
+	if err != nil || page == nil || len(posts) != 0 || page.HasNext() {
+		t.Errorf("Expect empty page in func TestScanCursorEmpty: %v, %+v, %v", posts, page, err)
+	}
+}
+
+func TestCursorSecret(t *testing.T) {
+	CursorSecret([]byte("secret"))
+	cursor, _ := encodeCursor(reflect.ValueOf(cursorPost{Id: 7, Score: 50}), []orderComponent{{"id", "asc"}}, false)
+
+	CursorSecret(nil)
+	defer CursorSecret([]byte("secret"))
+
+	db, _ := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		return fakeResponse{columns: []string{"id", "score"}, rows: [][]sqldriver.Value{{int64(1), int64(30)}, {int64(2), int64(20)}}}
+	})
+	defer db.Close()
+
+	posts := []cursorPost{}
+	if _, err := DataBase(db).Query(Table("posts").CursorPaginate([]string{"id"}, cursor, 1)).ScanCursor(&posts); err == nil || err == ErrInvalidCursor {
+		t.Errorf("Expect undefined secret error in func TestCursorSecret: %v", err)
+	}
+	if _, err := DataBase(db).Query(Table("posts").CursorPaginate([]string{"id"}, "", 1)).ScanCursor(&posts); err == nil {
+		t.Errorf("Expect undefined secret error in func TestCursorSecret")
+	}
+}
@@ -228,3 +228,58 @@ func DataEqual(a, b []interface{}) bool {
 	}
 	return true
 }
+
+func TestDataUpsert(t *testing.T) {
+	expect := []interface{}{1, "/", 1, "/news", 1, "2018-01-01"}
+	result := Table("counters").Upsert([]Data{{"page": "/", "hits": 1}, {"page": "/news", "hits": 1}}, []string{"page"}, Data{
+		"hits":    Raw(`"counters"."hits" + ?`, 1),
+		"updated": "2018-01-01",
+	}).Data()
+	if !DataEqual(result, expect) {
+		t.Errorf("Expect result to equal in func TestDataUpsert.\nResult: %v\nExpect: %v", result, expect)
+	}
+}
+
+func TestDataWith(t *testing.T) {
+	expect := []interface{}{1, 5, "news", 100}
+	anchor := Table("categories").Select("id", "parent_id").Where("id", "=", 1)
+	recursive := Table("categories c").Select("c.id", "c.parent_id").Join("tree", func(j *Joiner) {
+		j.On("tree.id", "=", "c.parent_id").Where("c.depth", "<", 5)
+	})
+	result := Table("tree").WithRecursive("tree", []string{"id", "parent_id"}, anchor, recursive).Where("type", "=", "news").Limit(100).Data()
+	if !DataEqual(result, expect) {
+		t.Errorf("Expect result to equal in func TestDataWith.\nResult: %v\nExpect: %v", result, expect)
+	}
+}
+
+func TestDataUnion(t *testing.T) {
+	expect := []interface{}{"active", 2, 5, 10, 20}
+	admins := Table("admins").Select("id", "name").Where("level", ">", 2).Limit(5)
+	result := Table("users").Select("id", "name").Limit(10).Offset(20).Where("status", "=", "active").Union(admins).Data()
+	if !DataEqual(result, expect) {
+		t.Errorf("Expect result to equal in func TestDataUnion.\nResult: %v\nExpect: %v", result, expect)
+	}
+}
+
+func TestDataJoinSub(t *testing.T) {
+	expect := []interface{}{"paid", "2018-01-01", "admin", "manager", 10}
+	orders := Table("orders").Select("user_id").Where("status", "=", "paid")
+	result := Table("users as us").JoinSub(orders, "o", func(joiner *Joiner) {
+		joiner.On("o.user_id", "=", "us.id").OnGroup(func(joiner *Joiner) {
+			joiner.Where("o.last", ">", "2018-01-01").OrWhereNull("o.last")
+		}).WhereIn("us.role", List{"admin", "manager"})
+	}).Where("us.id", ">", 10).Data()
+	if !DataEqual(result, expect) {
+		t.Errorf("Expect result to equal in func TestDataJoinSub.\nResult: %v\nExpect: %v", result, expect)
+	}
+}
+
+func TestDataCase(t *testing.T) {
+	expect := []interface{}{1000, "gold", 100, "silver", "bronze", false, "paid"}
+	level := Case().When("total", ">", 1000, "gold").When("total", ">", 100, "silver").Else("bronze").As("level")
+	items := Table("items").Select(Fn("COUNT", Raw("*"))).Where("deleted", "=", false)
+	result := Table("orders").Select("id", level).SelectSub(items, "items").Where("status", "=", "paid").Data()
+	if !DataEqual(result, expect) {
+		t.Errorf("Expect result to equal in func TestDataCase.\nResult: %v\nExpect: %v", result, expect)
+	}
+}
@@ -0,0 +1,230 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Фейковый драйвер для проверки выполнения запросов без базы данных
+// Ответ на запрос формирует функция respond, все запросы записываются в calls
+
+type fakeResponse struct {
+	columns  []string
+	rows     [][]sqldriver.Value
+	lastId   int64
+	affected int64
+	err      error
+}
+
+type fakeCall struct {
+	query string
+	args  []interface{}
+}
+
+type fakeDB struct {
+	mutex    sync.Mutex
+	calls    []fakeCall
+	prepares int
+	closed   int
+	respond  func(query string, args []interface{}) fakeResponse
+}
+
+func (self *fakeDB) call(query string, args []sqldriver.NamedValue) fakeResponse {
+	values := make([]interface{}, len(args))
+	for k, v := range args {
+		values[k] = v.Value
+	}
+	self.mutex.Lock()
+	self.calls = append(self.calls, fakeCall{query, values})
+	self.mutex.Unlock()
+	if self.respond == nil {
+		return fakeResponse{}
+	}
+	return self.respond(query, values)
+}
+
+func (self *fakeDB) queries() []string {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	queries := make([]string, len(self.calls))
+	for k, v := range self.calls {
+		queries[k] = v.query
+	}
+	return queries
+}
+
+var (
+	fakeMutex sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("sqlxfake", fakeDriver{})
+}
+
+func newFakeDB(respond func(query string, args []interface{}) fakeResponse) (*sql.DB, *fakeDB) {
+	fakeMutex.Lock()
+	name := "fake" + strconv.Itoa(len(fakeDBs))
+	fake := &fakeDB{respond: respond}
+	fakeDBs[name] = fake
+	fakeMutex.Unlock()
+	db, err := sql.Open("sqlxfake", name)
+	if err != nil {
+		panic(err)
+	}
+	return db, fake
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (sqldriver.Conn, error) {
+	fakeMutex.Lock()
+	defer fakeMutex.Unlock()
+	fake, ok := fakeDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("fake database %q not found", name)
+	}
+	return &fakeConn{fake}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (self *fakeConn) Prepare(query string) (sqldriver.Stmt, error) {
+	self.db.mutex.Lock()
+	self.db.prepares++
+	self.db.mutex.Unlock()
+	return &fakeStmt{self, query}, nil
+}
+
+func (self *fakeConn) Close() error {
+	return nil
+}
+
+func (self *fakeConn) Begin() (sqldriver.Tx, error) {
+	return self.BeginTx(context.Background(), sqldriver.TxOptions{})
+}
+
+func (self *fakeConn) BeginTx(ctx context.Context, opts sqldriver.TxOptions) (sqldriver.Tx, error) {
+	res := self.db.call("BEGIN", nil)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &fakeTx{self}, nil
+}
+
+func (self *fakeConn) QueryContext(ctx context.Context, query string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	res := self.db.call(query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &fakeRows{columns: res.columns, rows: res.rows}, nil
+}
+
+func (self *fakeConn) ExecContext(ctx context.Context, query string, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	res := self.db.call(query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return fakeResult{res.lastId, res.affected}, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (self *fakeTx) Commit() error {
+	return self.conn.db.call("COMMIT", nil).err
+}
+
+func (self *fakeTx) Rollback() error {
+	return self.conn.db.call("ROLLBACK", nil).err
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (self *fakeStmt) Close() error {
+	self.conn.db.mutex.Lock()
+	self.conn.db.closed++
+	self.conn.db.mutex.Unlock()
+	return nil
+}
+
+func (self *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (self *fakeStmt) Exec(args []sqldriver.Value) (sqldriver.Result, error) {
+	return self.ExecContext(context.Background(), named(args))
+}
+
+func (self *fakeStmt) Query(args []sqldriver.Value) (sqldriver.Rows, error) {
+	return self.QueryContext(context.Background(), named(args))
+}
+
+func (self *fakeStmt) ExecContext(ctx context.Context, args []sqldriver.NamedValue) (sqldriver.Result, error) {
+	return self.conn.ExecContext(ctx, self.query, args)
+}
+
+func (self *fakeStmt) QueryContext(ctx context.Context, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	return self.conn.QueryContext(ctx, self.query, args)
+}
+
+func named(args []sqldriver.Value) []sqldriver.NamedValue {
+	values := make([]sqldriver.NamedValue, len(args))
+	for k, v := range args {
+		values[k] = sqldriver.NamedValue{Ordinal: k + 1, Value: v}
+	}
+	return values
+}
+
+type fakeResult struct {
+	lastId   int64
+	affected int64
+}
+
+func (self fakeResult) LastInsertId() (int64, error) {
+	return self.lastId, nil
+}
+
+func (self fakeResult) RowsAffected() (int64, error) {
+	return self.affected, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]sqldriver.Value
+	pos     int
+}
+
+func (self *fakeRows) Columns() []string {
+	return self.columns
+}
+
+func (self *fakeRows) Close() error {
+	return nil
+}
+
+func (self *fakeRows) Next(dest []sqldriver.Value) error {
+	if self.pos >= len(self.rows) {
+		return io.EOF
+	}
+	copy(dest, self.rows[self.pos])
+	self.pos++
+	return nil
+}
@@ -0,0 +1,259 @@
+package sqlx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Выражение, которое компилирует грамматика активного диалекта
+type clause interface {
+	sql(glammar) string
+	Data() []interface{}
+}
+
+// Значения для плейсхолдеров из набора колонок, значений и выражений
+func clauseData(values ...interface{}) []interface{} {
+	data := make([]interface{}, 0)
+	for _, v := range values {
+		switch x := v.(type) {
+		case Expression:
+			data = append(data, x.Data()...)
+		case clause:
+			data = append(data, x.Data()...)
+		}
+	}
+	return data
+}
+
+// SQL функция: Fn("COALESCE", "nickname", "name")
+// Аргументы - колонки, sqlx.Raw или другие функции
+type Func struct {
+	name     string
+	distinct bool
+	args     []interface{}
+	window   *Window
+	alias    string
+}
+
+var _ clause = (*Func)(nil)
+
+func Fn(name string, args ...interface{}) *Func {
+	return &Func{
+		name: name,
+		args: args,
+	}
+}
+
+// COUNT(DISTINCT ...)
+func CountDistinct(columns ...interface{}) *Func {
+	f := Fn("COUNT", columns...)
+	f.distinct = true
+	return f
+}
+
+func RowNumber() *Func {
+	return Fn("ROW_NUMBER")
+}
+
+func Rank() *Func {
+	return Fn("RANK")
+}
+
+func DenseRank() *Func {
+	return Fn("DENSE_RANK")
+}
+
+// Значение колонки на offset строк раньше в окне
+func Lag(column interface{}, offset int) *Func {
+	return Fn("LAG", column, Raw(strconv.Itoa(offset)))
+}
+
+// Значение колонки на offset строк позже в окне
+func Lead(column interface{}, offset int) *Func {
+	return Fn("LEAD", column, Raw(strconv.Itoa(offset)))
+}
+
+// Оконная функция: RowNumber().Over(PartitionBy("user_id").OrderBy("id", "DESC"))
+func (self *Func) Over(window *Window) *Func {
+	self.window = window
+	return self
+}
+
+func (self *Func) As(alias string) *Func {
+	self.alias = alias
+	return self
+}
+
+func (self *Func) sql(g glammar) string {
+	args := ""
+	if len(self.args) > 0 {
+		args = g.wrap(self.args...)
+	}
+	if self.distinct {
+		args = "DISTINCT " + args
+	}
+	result := self.name + "(" + args + ")"
+	if self.window != nil {
+		result += " " + self.window.sql(g)
+	}
+	if self.alias != "" {
+		result += " as " + g.wrapQuote(self.alias)
+	}
+	return result
+}
+
+func (self *Func) Data() []interface{} {
+	data := clauseData(self.args...)
+	if self.window != nil {
+		data = append(data, self.window.Data()...)
+	}
+	return data
+}
+
+// Описание окна для OVER ( ... )
+type Window struct {
+	partition []interface{}
+	order     []orderComponent
+}
+
+func NewWindow() *Window {
+	return &Window{}
+}
+
+// Helper для создания окна с PARTITION BY
+func PartitionBy(columns ...interface{}) *Window {
+	return NewWindow().PartitionBy(columns...)
+}
+
+func (self *Window) PartitionBy(columns ...interface{}) *Window {
+	self.partition = append(self.partition, columns...)
+	return self
+}
+
+// Направление asc или desc в любом регистре, иначе запрос вернет ошибку
+func (self *Window) OrderBy(column string, direction string) *Window {
+	self.order = append(self.order, orderComponent{column, direction})
+	return self
+}
+
+func (self *Window) sql(g glammar) string {
+	buff := make([]string, 0, 2)
+	if len(self.partition) > 0 {
+		buff = append(buff, "PARTITION BY "+g.wrap(self.partition...))
+	}
+	if len(self.order) > 0 {
+		order := make([]string, len(self.order))
+		for k, v := range self.order {
+			if !strings.EqualFold(v.direction, "asc") && !strings.EqualFold(v.direction, "desc") {
+				g.fail(errors.New("sqlx: window order direction must be asc or desc"))
+				return ""
+			}
+			order[k] = g.wrap(v.column) + " " + v.direction
+		}
+		buff = append(buff, "ORDER BY "+strings.Join(order, ", "))
+	}
+	return combine("OVER (", strings.Join(buff, " "), ")")
+}
+
+func (self *Window) Data() []interface{} {
+	return clauseData(self.partition...)
+}
+
+// Выражение CASE WHEN ... THEN ... ELSE ... END
+// Значения условий и результаты передаются через плейсхолдеры
+type CaseWhen struct {
+	whens []caseWhenComponent
+	other []interface{}
+	alias string
+}
+
+type caseWhenComponent struct {
+	column   interface{}
+	operator string
+	value    interface{}
+	result   interface{}
+}
+
+var _ clause = (*CaseWhen)(nil)
+
+func Case() *CaseWhen {
+	return &CaseWhen{}
+}
+
+func (self *CaseWhen) When(column interface{}, operator string, value interface{}, result interface{}) *CaseWhen {
+	if !isOperator(operator) {
+		panic("sqlx: such a \"operator\" is not allowed")
+	}
+	self.whens = append(self.whens, caseWhenComponent{column, operator, value, result})
+	return self
+}
+
+func (self *CaseWhen) Else(result interface{}) *CaseWhen {
+	self.other = []interface{}{result}
+	return self
+}
+
+func (self *CaseWhen) As(alias string) *CaseWhen {
+	self.alias = alias
+	return self
+}
+
+func (self *CaseWhen) sql(g glammar) string {
+	if len(self.whens) == 0 {
+		g.fail(errors.New("sqlx: case requires at least one when"))
+		return ""
+	}
+	buff := make([]string, 0, len(self.whens)+3)
+	buff = append(buff, "CASE")
+	for _, v := range self.whens {
+		buff = append(buff, combine("WHEN", g.wrap(v.column), v.operator, g.parameter(v.value), "THEN", g.parameter(v.result)))
+	}
+	if len(self.other) > 0 {
+		buff = append(buff, "ELSE "+g.parameter(self.other...))
+	}
+	buff = append(buff, "END")
+	result := strings.Join(buff, " ")
+	if self.alias != "" {
+		result += " as " + g.wrapQuote(self.alias)
+	}
+	return result
+}
+
+func (self *CaseWhen) Data() []interface{} {
+	data := make([]interface{}, 0, len(self.whens)*2+1)
+	for _, v := range self.whens {
+		data = append(data, clauseData(v.column)...)
+		data = append(data, bindValue(v.value)...)
+		data = append(data, bindValue(v.result)...)
+	}
+	for _, v := range self.other {
+		data = append(data, bindValue(v)...)
+	}
+	return data
+}
+
+// Значение для плейсхолдера, у выражений - их собственные значения
+func bindValue(v interface{}) []interface{} {
+	switch x := v.(type) {
+	case Expression:
+		return x.Data()
+	case clause:
+		return x.Data()
+	}
+	return []interface{}{v}
+}
+
+// Подзапрос в Select: ( SELECT ... ) as alias
+type subSelect struct {
+	builder *Builder
+	alias   string
+}
+
+func (self subSelect) sql(g glammar) string {
+	return combine("(", g.compile(self.builder), ")", "as", g.wrapQuote(self.alias))
+}
+
+func (self subSelect) Data() []interface{} {
+	return self.builder.rawData()
+}
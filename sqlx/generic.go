@@ -0,0 +1,114 @@
+package sqlx
+
+import (
+	"context"
+)
+
+// Типизированные запросы
+// T - структура, map[string]interface{} или значение первой колонки.
+// Соответствие колонок полям структуры вычисляется один раз для каждого типа.
+//
+//	user, err := sqlx.Get[User](ctx, db, sqlx.Table("users").Where("id", "=", 1))
+//	users, err := sqlx.Select[User](ctx, db, sqlx.Table("users"))
+//	ids, err := sqlx.Select[int64](ctx, db, sqlx.Table("users").Select("id"))
+
+// Первая строка результата, ErrNoRows если строк нет
+func Get[T any](ctx context.Context, db Querier, builder *Builder) (T, error) {
+	var value T
+
+	rows, err := db.Query(builder).RowsContext(ctx)
+	if err != nil {
+		return value, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return value, err
+		}
+		return value, ErrNoRows
+	}
+
+	if err := rowScanner[T](rows)(&value); err != nil {
+		return value, err
+	}
+
+	return value, rows.Close()
+}
+
+// Все строки результата, ErrNoRows если строк нет
+func Select[T any](ctx context.Context, db Querier, builder *Builder) ([]T, error) {
+	rows, err := db.Query(builder).RowsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scan := rowScanner[T](rows)
+	values := make([]T, 0)
+
+	for rows.Next() {
+		var value T
+		if err := scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return values, ErrNoRows
+	}
+
+	return values, rows.Close()
+}
+
+// Обработка результата частями по n строк
+// Если f возвращает false, обработка прекращается
+func Chunk[T any](ctx context.Context, db Querier, builder *Builder, n int, f func([]T) bool) error {
+	if n < 1 {
+		return errChunkSize
+	}
+	rows, err := db.Query(builder).RowsContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	scan := rowScanner[T](rows)
+	chunk := make([]T, 0, n)
+	found := false
+
+	for rows.Next() {
+		var value T
+		if err := scan(&value); err != nil {
+			return err
+		}
+		chunk = append(chunk, value)
+		found = true
+
+		if len(chunk) == n {
+			if !f(chunk) {
+				return rows.Close()
+			}
+			chunk = make([]T, 0, n)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(chunk) > 0 {
+		f(chunk)
+	}
+
+	if !found {
+		return ErrNoRows
+	}
+
+	return rows.Close()
+}
@@ -0,0 +1,60 @@
+package sqlx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGenericGet(t *testing.T) {
+	db, close := newScannerDB()
+	defer close()
+
+	type User struct {
+		Id   int
+		Name string
+	}
+
+	user, err := Get[User](context.Background(), db, Table("users"))
+	if err != nil || user.Id != 1 || user.Name != "Jack" {
+		t.Errorf("Unexpected get result in func TestGenericGet: %+v, %v", user, err)
+	}
+
+	id, err := Get[int64](context.Background(), db, Table("users").Select("id"))
+	if err != nil || id != 1 {
+		t.Errorf("Unexpected get result in func TestGenericGet: %v, %v", id, err)
+	}
+}
+
+func TestGenericSelect(t *testing.T) {
+	db, close := newScannerDB()
+	defer close()
+
+	type User struct {
+		Id   int
+		Name string
+	}
+
+	users, err := Select[User](context.Background(), db, Table("users"))
+	if err != nil || len(users) != 2 || users[1].Name != "Mike" {
+		t.Errorf("Unexpected select result in func TestGenericSelect: %+v, %v", users, err)
+	}
+
+	refs, err := Select[*User](context.Background(), db, Table("users"))
+	if err != nil || len(refs) != 2 || refs[1].Name != "Mike" {
+		t.Errorf("Unexpected select result in func TestGenericSelect: %+v, %v", refs, err)
+	}
+
+	chunks := [][]User{}
+	err = Chunk(context.Background(), db, Table("users"), 1, func(users []User) bool {
+		chunks = append(chunks, users)
+		return false
+	})
+	if err != nil || len(chunks) != 1 || chunks[0][0].Id != 1 {
+		t.Errorf("Unexpected chunk result in func TestGenericSelect: %+v, %v", chunks, err)
+	}
+
+	err = Chunk(context.Background(), db, Table("users"), 0, func(users []User) bool { return true })
+	if err == nil {
+		t.Errorf("Expect error for zero chunk size in func TestGenericSelect")
+	}
+}
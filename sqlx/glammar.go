@@ -1,6 +1,7 @@
 package sqlx
 
 import (
+	"errors"
 	"strings"
 )
 
@@ -13,10 +14,16 @@ type glammar interface {
 	parameter(...interface{}) string
 	prepareRaw(interface{}) string
 	combineSelect(*Builder) string
+	combineUnion(*Builder) string
+	wrapUnion(query string, b *Builder) string
+	wrapUnionChain(query string) string
 	combineInsert(*Builder) string
 	combineUpdate(*Builder) string
 	combineDelete(*Builder) string
 	compile(*Builder) string
+	fail(error)
+	failure() error
+	compileWith(*Builder) string
 	compileSelect(*Builder) string
 	compileFrom(*Builder) string
 	compileJoin(*Builder) string
@@ -35,16 +42,42 @@ type glammar interface {
 	compileValues(*Builder) string
 	compileOrIgnore(*Builder) string
 	compileOnConflictDoNothing(*Builder) string
+	compileUpsert(*Builder) string
 	compileReturning(*Builder) string
+	compileSchema(*Blueprint) ([]string, error)
+	expandCursor([]orderComponent) bool
+	columnType(*Column) string
+	columnModifiers(*Column) string
+	literalValue(interface{}) string
+	compileAddPrimary(*Blueprint, []string) (string, error)
+	compileAddForeign(*Blueprint, *schemaCommand) (string, error)
+	compileDropIndex(*Blueprint, *schemaCommand) string
+	compileDropForeign(*Blueprint, *schemaCommand) (string, error)
 }
 
 // Базовая граматика
 type baseGlammar struct {
 	glammar
+	// Первая ошибка компиляции, граматика создается на каждую компиляцию
+	err error
+}
+
+// Запоминает ошибку компиляции, запрос с ошибкой не выполняется
+func (self *baseGlammar) fail(err error) {
+	if self.err == nil {
+		self.err = err
+	}
+}
+
+func (self *baseGlammar) failure() error {
+	return self.err
 }
 
 // Комбинация Select
 func (self *baseGlammar) combineSelect(b *Builder) string {
+	if len(b.components.Union) > 0 {
+		return self.glammar.combineUnion(b)
+	}
 	return combine(
 		self.glammar.compileSelect(b),
 		self.glammar.compileFrom(b),
@@ -58,6 +91,47 @@ func (self *baseGlammar) combineSelect(b *Builder) string {
 	)
 }
 
+// Комбинация Select с объединениями
+// Сортировка и лимиты основного запроса относятся ко всему объединению
+func (self *baseGlammar) combineUnion(b *Builder) string {
+	query := combine(
+		self.glammar.compileSelect(b),
+		self.glammar.compileFrom(b),
+		self.glammar.compileJoin(b),
+		self.glammar.compileWhere(b),
+		self.glammar.compileGroup(b),
+		self.glammar.compileHaving(b),
+	)
+
+	// При смене оператора накопленная часть берется в скобки,
+	// чтобы операторы применялись по порядку вызова, а не по приоритету
+	chain := self.glammar.wrapUnion(query, nil)
+	for k, v := range b.components.Union {
+		if k > 0 && v.kind != b.components.Union[k-1].kind {
+			chain = self.glammar.wrapUnionChain(chain)
+		}
+		chain = combine(chain, v.kind, self.glammar.wrapUnion(self.glammar.compile(v.builder), v.builder))
+	}
+
+	return combine(
+		chain,
+		self.glammar.compileOrder(b),
+		self.glammar.compileLimit(b),
+		self.glammar.compileOffset(b),
+	)
+}
+
+// Часть объединения берется в скобки, чтобы ее сортировка и лимиты не относились ко всему запросу
+// b - присоединяемый строитель, nil для основного запроса
+func (self *baseGlammar) wrapUnion(query string, b *Builder) string {
+	return "( " + query + " )"
+}
+
+// Накопленная цепочка объединений перед сменой оператора
+func (self *baseGlammar) wrapUnionChain(query string) string {
+	return "( " + query + " )"
+}
+
 // Комбинация Delete
 func (self *baseGlammar) combineDelete(b *Builder) string {
 	return combine(
@@ -87,6 +161,7 @@ func (self *baseGlammar) combineInsert(b *Builder) string {
 		self.glammar.compileColumns(b),
 		self.glammar.compileValues(b),
 		self.glammar.compileOnConflictDoNothing(b),
+		self.glammar.compileUpsert(b),
 		self.glammar.compileReturning(b),
 	)
 }
@@ -96,6 +171,12 @@ func (self *baseGlammar) compileSelect(b *Builder) string {
 	buff := make([]interface{}, 0, len(b.components.Select)+len(b.components.Aggregate))
 	buff = append(buff, self.selectFields(b)...)
 	buff = append(buff, self.selectAggregates(b)...)
+	if len(buff) == 0 {
+		buff = append(buff, "*")
+	}
+	if len(b.components.Distinct) > 0 {
+		return "SELECT DISTINCT " + self.glammar.wrap(buff...)
+	}
 	return "SELECT " + self.glammar.wrap(buff...)
 }
 
@@ -151,35 +232,64 @@ func (self *baseGlammar) compileJoin(b *Builder) string {
 		return ""
 	}
 
-	buff1 := make([]string, len(b.components.Join))
+	buff := make([]string, len(b.components.Join))
 	for i, join := range b.components.Join {
-		buff2 := make([]string, len(join.conditions))
-		for j, v := range join.conditions {
-			var result string
-			switch v.kind {
-			case "on":
-				result = combine(self.glammar.wrap(v.column), v.operator, self.glammar.wrap(v.value))
-			case "where":
-				result = combine(self.glammar.wrap(v.column), v.operator, self.glammar.parameter(v.value))
+		table := self.glammar.wrap(join.table)
+		if join.builder != nil {
+			table = combine("(", self.glammar.compile(join.builder), ")", "as", self.glammar.wrap(join.table))
+			if join.lateral {
+				table = "LATERAL " + table
 			}
-			buff2[j] = combine(v.boolean, result)
 		}
 
-		buff1[i] = combine(join.kind, "JOIN", self.glammar.wrap(join.table), "ON (", strings.Join(buff2, " "), ")")
+		switch {
+		case join.kind == "CROSS":
+			buff[i] = combine("CROSS JOIN", table)
+		case len(join.conditions) == 0:
+			buff[i] = combine(join.kind, "JOIN", table, "ON TRUE")
+		default:
+			buff[i] = combine(join.kind, "JOIN", table, "ON (", self.joinConditions(join.conditions), ")")
+		}
 	}
 
-	return strings.Join(buff1, " ")
+	return strings.Join(buff, " ")
+}
+
+func (self *baseGlammar) joinConditions(conditions []joinCondition) string {
+	buff := make([]string, len(conditions))
+	for k, v := range conditions {
+		var result string
+		switch v.kind {
+		case "on":
+			result = combine(self.glammar.wrap(v.column), v.operator, self.glammar.wrap(v.value))
+		case "where":
+			result = combine(self.glammar.wrap(v.column), v.operator, self.glammar.parameter(v.value))
+		case "group":
+			result = "( " + self.joinConditions(v.joiner.conditions) + " )"
+		case "in":
+			result = self.inList(v.column, v.list, false)
+		case "notin":
+			result = self.inList(v.column, v.list, true)
+		case "null":
+			result = self.glammar.wrap(v.column) + " IS NULL"
+		case "notnull":
+			result = self.glammar.wrap(v.column) + " IS NOT NULL"
+		}
+		buff[k] = combine(v.boolean, result)
+	}
+	return strings.Join(buff, " ")
 }
 
 // Компиляция WHERE
 func (self *baseGlammar) compileWhere(b *Builder) string {
-	if len(b.components.Where) == 0 {
+	where := b.whereComponents()
+	if len(where) == 0 {
 		return ""
 	}
 
-	buff := make([]string, len(b.components.Where))
+	buff := make([]string, len(where))
 
-	for k, v := range b.components.Where {
+	for k, v := range where {
 		var result string
 		switch v.kind {
 		case "base":
@@ -204,6 +314,8 @@ func (self *baseGlammar) compileWhere(b *Builder) string {
 			result = self.whereInSub(v)
 		case "notinsub":
 			result = self.whereNotInSub(v)
+		case "cursor":
+			result = self.whereCursor(v)
 		}
 		buff[k] = combine(v.boolean, result)
 	}
@@ -211,6 +323,42 @@ func (self *baseGlammar) compileWhere(b *Builder) string {
 	return "WHERE " + strings.Join(buff, " ")
 }
 
+// Строки после курсора: сравнение строк при одинаковом направлении сортировки,
+// иначе раскрытые сравнения по каждой колонке
+func (self *baseGlammar) whereCursor(w whereComponent) string {
+	order := w.column.([]orderComponent)
+	operator := func(o orderComponent) string {
+		if o.direction == "desc" {
+			return "<"
+		}
+		return ">"
+	}
+
+	if !self.glammar.expandCursor(order) {
+		columns := make([]interface{}, len(order))
+		for k, o := range order {
+			columns[k] = o.column
+		}
+		return combine("(", self.glammar.wrap(columns...), ")", operator(order[0]), "(", self.glammar.parameter(w.list...), ")")
+	}
+
+	buff := make([]string, len(order))
+	for k, o := range order {
+		parts := make([]string, 0, k+1)
+		for i := 0; i < k; i++ {
+			parts = append(parts, combine(self.glammar.wrap(order[i].column), "=", self.glammar.parameter(w.list[i])))
+		}
+		parts = append(parts, combine(self.glammar.wrap(o.column), operator(o), self.glammar.parameter(w.list[k])))
+		buff[k] = "( " + strings.Join(parts, " AND ") + " )"
+	}
+	return "( " + strings.Join(buff, " OR ") + " )"
+}
+
+// Раскрытые сравнения вместо сравнения строк, нужны при смешанном направлении
+func (self *baseGlammar) expandCursor(order []orderComponent) bool {
+	return !uniformDirection(order)
+}
+
 func (self *baseGlammar) whereBase(w whereComponent) string {
 	return combine(self.glammar.wrap(w.column), w.operator, self.glammar.parameter(w.value))
 }
@@ -224,11 +372,26 @@ func (self *baseGlammar) whereRaw(w whereComponent) string {
 }
 
 func (self *baseGlammar) whereIn(w whereComponent) string {
-	return combine(self.glammar.wrap(w.column), "IN (", self.glammar.parameter(w.list...), ")")
+	return self.inList(w.column, w.list, false)
 }
 
 func (self *baseGlammar) whereNotIn(w whereComponent) string {
-	return combine(self.glammar.wrap(w.column), "NOT IN (", self.glammar.parameter(w.list...), ")")
+	return self.inList(w.column, w.list, true)
+}
+
+// IN по списку значений, пустой список: IN всегда ложно, NOT IN всегда истинно
+func (self *baseGlammar) inList(column interface{}, list []interface{}, not bool) string {
+	if len(list) == 0 {
+		if not {
+			return "1 = 1"
+		}
+		return "0 = 1"
+	}
+	operator := "IN ("
+	if not {
+		operator = "NOT IN ("
+	}
+	return combine(self.glammar.wrap(column), operator, self.glammar.parameter(list...), ")")
 }
 
 func (self *baseGlammar) whereInSub(w whereComponent) string {
@@ -385,23 +548,107 @@ func (self *baseGlammar) compileOnConflictDoNothing(b *Builder) string {
 	return ""
 }
 
-// Заглушка для Insert Returning id
+// Заглушка для Insert с обновлением при конфликте
+func (self *baseGlammar) compileUpsert(b *Builder) string {
+	return ""
+}
+
+// Список присваиваний для обновления при конфликте
+// excluded формирует ссылку на вставляемое значение колонки
+func (self *baseGlammar) upsertSet(u upsertComponent, excluded func(string) string) string {
+	buff := make([]string, 0, len(u.columns)+len(u.set))
+	for _, c := range u.columns {
+		buff = append(buff, combine(self.glammar.wrap(c), "=", excluded(c)))
+	}
+	for _, k := range u.set.Keys() {
+		buff = append(buff, combine(self.glammar.wrap(k), "=", self.glammar.parameter(u.set[k])))
+	}
+	return strings.Join(buff, ", ")
+}
+
+// ON CONFLICT (...) DO UPDATE SET для PostgreSQL и SQLite
+func (self *baseGlammar) onConflictUpdate(b *Builder) string {
+	if len(b.components.Upsert) == 0 {
+		return ""
+	}
+	u := b.components.Upsert[0]
+	if len(u.conflict) == 0 {
+		self.glammar.fail(errors.New("sqlx: upsert requires conflict columns"))
+		return ""
+	}
+	conflict := make([]interface{}, len(u.conflict))
+	for k, v := range u.conflict {
+		conflict[k] = v
+	}
+	set := self.upsertSet(u, func(column string) string {
+		return "EXCLUDED." + self.glammar.wrap(column)
+	})
+	return combine("ON CONFLICT (", self.glammar.wrap(conflict...), ") DO UPDATE SET", set)
+}
+
+// Заглушка для Returning
 func (self *baseGlammar) compileReturning(b *Builder) string {
 	return ""
 }
 
+// RETURNING для PostgreSQL и SQLite
+func (self *baseGlammar) returning(b *Builder) string {
+	if len(b.components.Returning) == 0 {
+		return ""
+	}
+	return "RETURNING " + self.glammar.wrap(b.components.Returning...)
+}
+
+// Компиляция With
+func (self *baseGlammar) compileWith(b *Builder) string {
+	if len(b.components.With) == 0 {
+		return ""
+	}
+
+	recursive := false
+	buff := make([]string, len(b.components.With))
+
+	for k, v := range b.components.With {
+		columns := ""
+		if len(v.columns) > 0 {
+			list := make([]interface{}, len(v.columns))
+			for i, c := range v.columns {
+				list[i] = c
+			}
+			columns = "( " + self.glammar.wrap(list...) + " )"
+		}
+
+		query := self.glammar.compile(v.builder)
+		if v.recursive != nil {
+			recursive = true
+			query = combine(query, "UNION ALL", self.glammar.compile(v.recursive))
+		}
+
+		buff[k] = combine(self.glammar.wrap(v.name), columns, "AS (", query, ")")
+	}
+
+	if recursive {
+		return "WITH RECURSIVE " + strings.Join(buff, ", ")
+	}
+	return "WITH " + strings.Join(buff, ", ")
+}
+
 // Компилируем Builder
 func (self *baseGlammar) compile(b *Builder) string {
-	result := ""
+	if b.err != nil {
+		self.glammar.fail(b.err)
+	}
+	// WITH компилируется первым, его плейсхолдеры идут перед остальными
+	result := self.glammar.compileWith(b)
 	switch b.kind {
-	case "select":
-		result = self.glammar.combineSelect(b)
+	case "select", "":
+		result = combine(result, self.glammar.combineSelect(b))
 	case "insert":
-		result = self.glammar.combineInsert(b)
+		result = combine(result, self.glammar.combineInsert(b))
 	case "update":
-		result = self.glammar.combineUpdate(b)
+		result = combine(result, self.glammar.combineUpdate(b))
 	case "delete":
-		result = self.glammar.combineDelete(b)
+		result = combine(result, self.glammar.combineDelete(b))
 	}
 	return result
 }
@@ -414,6 +661,10 @@ func (self *baseGlammar) wrap(values ...interface{}) string {
 			buf[k] = self.glammar.prepareRaw(exp)
 			continue
 		}
+		if c, ok := v.(clause); ok {
+			buf[k] = c.sql(self.glammar)
+			continue
+		}
 
 		str := toString(v)
 		segments := strings.Fields(str)
@@ -460,6 +711,8 @@ func (self *baseGlammar) parameter(p ...interface{}) string {
 	for k, v := range p {
 		if exp, ok := v.(Expression); ok {
 			params[k] = self.prepareRaw(exp)
+		} else if c, ok := v.(clause); ok {
+			params[k] = c.sql(self.glammar)
 		} else {
 			params[k] = self.placeholder()
 		}
@@ -1,5 +1,9 @@
 package sqlx
 
+import (
+	"errors"
+)
+
 type mysqlGlammar struct {
 	baseGlammar
 }
@@ -16,6 +20,22 @@ func newMysqlGlammar() glammar {
 	return g
 }
 
+// MySQL не использует индекс для сравнения строк ( a, b ) > ( ?, ? ),
+// поэтому условие курсора всегда раскрывается
+func (self *mysqlGlammar) expandCursor(order []orderComponent) bool {
+	return true
+}
+
+// MySQL не поддерживает FULL OUTER JOIN
+func (self *mysqlGlammar) compileJoin(b *Builder) string {
+	for _, join := range b.components.Join {
+		if join.kind == "FULL OUTER" {
+			self.fail(errors.New("sqlx: mysql does not support full outer join"))
+		}
+	}
+	return self.baseGlammar.compileJoin(b)
+}
+
 // Вставка Insert IGNORE
 func (self *mysqlGlammar) compileOrIgnore(b *Builder) string {
 	if len(b.components.OrIgnore) == 0 {
@@ -23,3 +43,14 @@ func (self *mysqlGlammar) compileOrIgnore(b *Builder) string {
 	}
 	return "IGNORE"
 }
+
+// Вставка Insert ON DUPLICATE KEY UPDATE
+func (self *mysqlGlammar) compileUpsert(b *Builder) string {
+	if len(b.components.Upsert) == 0 {
+		return ""
+	}
+	set := self.upsertSet(b.components.Upsert[0], func(column string) string {
+		return "VALUES(" + self.wrap(column) + ")"
+	})
+	return "ON DUPLICATE KEY UPDATE " + set
+}
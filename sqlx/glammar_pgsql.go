@@ -56,6 +56,8 @@ func (self *pgsqlGlammar) parameter(p ...interface{}) string {
 	for k, v := range p {
 		if exp, ok := v.(Expression); ok {
 			params[k] = self.prepareRaw(exp)
+		} else if c, ok := v.(clause); ok {
+			params[k] = c.sql(self)
 		} else {
 			self.placeholders++
 			params[k] = self.placeholder()
@@ -76,12 +78,9 @@ func (self *pgsqlGlammar) prepareRaw(p interface{}) string {
 	return toString(p)
 }
 
-// Вставка Insert Returning id
+// Returning для Insert, Update и Delete
 func (self *pgsqlGlammar) compileReturning(b *Builder) string {
-	if len(b.components.ReturnId) == 0 {
-		return ""
-	}
-	return "RETURNING " + self.wrap("id")
+	return self.returning(b)
 }
 
 // Вставка Insert ON CONFLICT DO NOTHING
@@ -91,3 +90,50 @@ func (self *pgsqlGlammar) compileOnConflictDoNothing(b *Builder) string {
 	}
 	return "ON CONFLICT DO NOTHING"
 }
+
+// Вставка Insert ON CONFLICT DO UPDATE
+func (self *pgsqlGlammar) compileUpsert(b *Builder) string {
+	return self.onConflictUpdate(b)
+}
+
+// Типы колонок PostgreSQL
+var pgsqlColumnTypes = map[string]string{
+	"increments":    "SERIAL PRIMARY KEY",
+	"bigIncrements": "BIGSERIAL PRIMARY KEY",
+	"integer":       "INTEGER",
+	"smallInteger":  "SMALLINT",
+	"bigInteger":    "BIGINT",
+	"float":         "REAL",
+	"double":        "DOUBLE PRECISION",
+	"decimal":       "NUMERIC({precision}, {scale})",
+	"boolean":       "BOOLEAN",
+	"string":        "VARCHAR({length})",
+	"text":          "TEXT",
+	"binary":        "BYTEA",
+	"json":          "JSONB",
+	"uuid":          "UUID",
+	"date":          "DATE",
+	"time":          "TIME",
+	"dateTime":      "TIMESTAMP",
+	"timestamp":     "TIMESTAMP",
+}
+
+func (self *pgsqlGlammar) columnType(c *Column) string {
+	return self.typeColumn(pgsqlColumnTypes, c)
+}
+
+func (self *pgsqlGlammar) columnModifiers(c *Column) string {
+	return columnModifiers(self, c)
+}
+
+func (self *pgsqlGlammar) literalValue(v interface{}) string {
+	return literal("postgres", v)
+}
+
+func (self *pgsqlGlammar) compileDropIndex(b *Blueprint, c *schemaCommand) string {
+	return "DROP INDEX " + self.wrap(c.name)
+}
+
+func (self *pgsqlGlammar) compileDropForeign(b *Blueprint, c *schemaCommand) (string, error) {
+	return "ALTER TABLE " + self.wrap(b.table) + " DROP CONSTRAINT " + self.wrap(c.name), nil
+}
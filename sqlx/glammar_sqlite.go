@@ -1,5 +1,9 @@
 package sqlx
 
+import (
+	"errors"
+)
+
 type sqliteGlammar struct {
 	baseGlammar
 }
@@ -23,3 +27,82 @@ func (self *sqliteGlammar) compileOrIgnore(b *Builder) string {
 	}
 	return "OR IGNORE"
 }
+
+// Вставка Insert ON CONFLICT DO UPDATE
+func (self *sqliteGlammar) compileUpsert(b *Builder) string {
+	return self.onConflictUpdate(b)
+}
+
+// Returning для Insert, Update и Delete, SQLite 3.35+
+func (self *sqliteGlammar) compileReturning(b *Builder) string {
+	return self.returning(b)
+}
+
+// SQLite не допускает скобки вокруг частей объединения,
+// части с сортировкой, лимитами или своими объединениями оборачиваются в подзапрос
+func (self *sqliteGlammar) wrapUnion(query string, b *Builder) string {
+	if b == nil {
+		return query
+	}
+	c := b.components
+	if len(c.Order) > 0 || len(c.Limit) > 0 || len(c.Offset) > 0 || len(c.Union) > 0 {
+		return "SELECT * FROM ( " + query + " )"
+	}
+	return query
+}
+
+// Цепочка объединений перед сменой оператора оборачивается в подзапрос
+func (self *sqliteGlammar) wrapUnionChain(query string) string {
+	return "SELECT * FROM ( " + query + " )"
+}
+
+// Типы колонок SQLite
+var sqliteColumnTypes = map[string]string{
+	"increments":    "INTEGER PRIMARY KEY AUTOINCREMENT",
+	"bigIncrements": "INTEGER PRIMARY KEY AUTOINCREMENT",
+	"integer":       "INTEGER",
+	"smallInteger":  "INTEGER",
+	"bigInteger":    "INTEGER",
+	"float":         "REAL",
+	"double":        "REAL",
+	"decimal":       "NUMERIC",
+	"boolean":       "INTEGER",
+	"string":        "VARCHAR({length})",
+	"text":          "TEXT",
+	"binary":        "BLOB",
+	"json":          "TEXT",
+	"uuid":          "CHAR(36)",
+	"date":          "DATE",
+	"time":          "TIME",
+	"dateTime":      "DATETIME",
+	"timestamp":     "DATETIME",
+}
+
+func (self *sqliteGlammar) columnType(c *Column) string {
+	return self.typeColumn(sqliteColumnTypes, c)
+}
+
+func (self *sqliteGlammar) columnModifiers(c *Column) string {
+	return columnModifiers(self, c)
+}
+
+func (self *sqliteGlammar) literalValue(v interface{}) string {
+	return literal("sqlite3", v)
+}
+
+// SQLite изменяет первичный и внешние ключи только пересозданием таблицы
+func (self *sqliteGlammar) compileAddPrimary(b *Blueprint, columns []string) (string, error) {
+	return "", errors.New("sqlx: sqlite3 does not support adding a primary key to an existing table")
+}
+
+func (self *sqliteGlammar) compileAddForeign(b *Blueprint, c *schemaCommand) (string, error) {
+	return "", errors.New("sqlx: sqlite3 does not support adding foreign keys to an existing table")
+}
+
+func (self *sqliteGlammar) compileDropIndex(b *Blueprint, c *schemaCommand) string {
+	return "DROP INDEX " + self.wrap(c.name)
+}
+
+func (self *sqliteGlammar) compileDropForeign(b *Blueprint, c *schemaCommand) (string, error) {
+	return "", errors.New("sqlx: sqlite3 does not support dropping foreign keys")
+}
@@ -0,0 +1,115 @@
+package sqlx
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Событие выполнения запроса
+type QueryEvent struct {
+	// Строка запроса с плейсхолдерами и данные для них
+	Query string
+	Args  []interface{}
+	// Диалект подключения
+	Dialect string
+	// Время начала и продолжительность выполнения
+	// Для выборок учитывается время до получения первого ответа, без чтения строк
+	Start    time.Time
+	Duration time.Duration
+	// Количество затронутых строк, для выборок -1
+	RowsAffected int64
+	Err          error
+}
+
+// Строка запроса с подставленными данными
+// Используется только для журналов, не для выполнения
+func (self *QueryEvent) SQL() string {
+	return Interpolate(self.Dialect, self.Query, self.Args)
+}
+
+// Хук выполнения запросов
+// BeforeQuery вызывается перед запросом и может вернуть новый контекст,
+// например со span трассировки, который будет передан в запрос и AfterQuery.
+// AfterQuery вызывается в обратном порядке добавления хуков.
+type Hook interface {
+	BeforeQuery(ctx context.Context, event *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// Хук из функции, вызываемой после выполнения запроса
+//
+//	dbx.AddHook(sqlx.AfterQueryFunc(func(ctx context.Context, event *sqlx.QueryEvent) {
+//	    metrics.Observe(event.Duration)
+//	}))
+type AfterQueryFunc func(ctx context.Context, event *QueryEvent)
+
+func (self AfterQueryFunc) BeforeQuery(ctx context.Context, event *QueryEvent) context.Context {
+	return ctx
+}
+
+func (self AfterQueryFunc) AfterQuery(ctx context.Context, event *QueryEvent) {
+	self(ctx, event)
+}
+
+// Добавить хук выполнения запросов
+// Хуки наследуются транзакциями и выделенными соединениями,
+// добавлять их следует до начала работы с подключением
+func (self *DB) AddHook(hook Hook) *DB {
+	self.hooks = append(self.hooks[:len(self.hooks):len(self.hooks)], hook)
+	return self
+}
+
+// Журнал медленных запросов
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Хук, записывающий в журнал запросы дольше threshold
+// вместе с продолжительностью и подставленными данными.
+// Нулевой threshold записывает все запросы, nil logger - стандартный журнал log
+//
+//	dbx.AddHook(sqlx.SlowQueryLogger(200*time.Millisecond, log.New(os.Stderr, "", log.LstdFlags)))
+func SlowQueryLogger(threshold time.Duration, logger Logger) Hook {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return AfterQueryFunc(func(ctx context.Context, event *QueryEvent) {
+		if event.Duration < threshold {
+			return
+		}
+		if event.Err != nil {
+			logger.Printf("sqlx: slow query %s: %s; error: %s", event.Duration, event.SQL(), event.Err)
+			return
+		}
+		logger.Printf("sqlx: slow query %s: %s", event.Duration, event.SQL())
+	})
+}
+
+// Вызов хуков перед запросом, nil событие если хуков нет
+func (self *Query) before(ctx context.Context) (context.Context, *QueryEvent) {
+	if len(self.hooks) == 0 {
+		return ctx, nil
+	}
+
+	event := &QueryEvent{
+		Query:        self.query,
+		Args:         self.data,
+		Dialect:      self.dialect,
+		RowsAffected: -1,
+	}
+	for _, hook := range self.hooks {
+		ctx = hook.BeforeQuery(ctx, event)
+	}
+	event.Start = time.Now()
+
+	return ctx, event
+}
+
+func (self *Query) after(ctx context.Context, event *QueryEvent, err error) {
+	event.Duration = time.Since(event.Start)
+	event.Err = err
+	for i := len(self.hooks) - 1; i >= 0; i-- {
+		self.hooks[i].AfterQuery(ctx, event)
+	}
+}
@@ -0,0 +1,125 @@
+package sqlx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+type hookKey struct{}
+
+type recordHook struct {
+	name   string
+	calls  *[]string
+	events []QueryEvent
+}
+
+func (self *recordHook) BeforeQuery(ctx context.Context, event *QueryEvent) context.Context {
+	*self.calls = append(*self.calls, "before "+self.name)
+	return context.WithValue(ctx, hookKey{}, self.name)
+}
+
+func (self *recordHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	*self.calls = append(*self.calls, "after "+self.name+" "+ctx.Value(hookKey{}).(string))
+	self.events = append(self.events, *event)
+}
+
+func TestQueryHooks(t *testing.T) {
+	failed := errors.New("failed")
+	db, _ := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		if strings.HasPrefix(query, "DELETE") {
+			return fakeResponse{err: failed}
+		}
+		return fakeResponse{affected: 3}
+	})
+	defer db.Close()
+
+	calls := []string{}
+	first := &recordHook{name: "first", calls: &calls}
+	second := &recordHook{name: "second", calls: &calls}
+	dbx := DataBase(db).AddHook(first).AddHook(second)
+
+	if _, err := dbx.Query(Table("users").Where("id", "=", 1).Update(Data{"name": "Jack"})).Exec(); err != nil {
+		t.Fatalf("Unexpected error in func TestQueryHooks: %v", err)
+	}
+
+	expect := []string{"before first", "before second", "after second second", "after first second"}
+	if strings.Join(calls, ", ") != strings.Join(expect, ", ") {
+		t.Errorf("Expect result to equal in func TestQueryHooks.\nResult: %q\nExpect: %q", calls, expect)
+	}
+
+	event := first.events[0]
+	if event.Query != `UPDATE "users" SET "name" = $1 WHERE "id" = $2` || !DataEqual(event.Args, []interface{}{"Jack", 1}) ||
+		event.RowsAffected != 3 || event.Err != nil || event.Dialect != "postgres" || event.Start.IsZero() {
+		t.Errorf("Unexpected event in func TestQueryHooks: %+v", event)
+	}
+
+	tx, err := dbx.Begin()
+	if err != nil {
+		t.Fatalf("Unexpected error in func TestQueryHooks: %v", err)
+	}
+	if _, err := tx.Query(Table("users").Where("id", "=", 1).Delete()).Exec(); err != failed {
+		t.Errorf("Expect query error in func TestQueryHooks: %v", err)
+	}
+	tx.Rollback()
+
+	if event := first.events[1]; event.Err != failed || event.RowsAffected != -1 {
+		t.Errorf("Unexpected event in func TestQueryHooks: %+v", event)
+	}
+}
+
+func TestSlowQueryLogger(t *testing.T) {
+	db, _ := newFakeDB(nil)
+	defer db.Close()
+
+	buf := &bytes.Buffer{}
+	logger := log.New(buf, "", 0)
+
+	dbx := DataBase(db).AddHook(SlowQueryLogger(time.Hour, logger))
+	if _, err := dbx.Query(Table("users").Where("name", "=", "Jack").Delete()).Exec(); err != nil || buf.Len() != 0 {
+		t.Errorf("Expect fast query to be skipped in func TestSlowQueryLogger: %q, %v", buf.String(), err)
+	}
+
+	dbx = DataBase(db).AddHook(SlowQueryLogger(0, logger))
+	if _, err := dbx.Query(Table("users").Where("name", "=", "Jack").Delete()).Exec(); err != nil {
+		t.Fatalf("Unexpected error in func TestSlowQueryLogger: %v", err)
+	}
+
+	expect := `: DELETE FROM "users" WHERE "name" = 'Jack'`
+	if result := buf.String(); !strings.HasPrefix(result, "sqlx: slow query ") || !strings.HasSuffix(result, expect+"\n") {
+		t.Errorf("Expect result to equal in func TestSlowQueryLogger.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	var null *string
+
+	expect := `SELECT * FROM "users" WHERE "name" = 'O''Neil' AND "note" = '$1' AND "id" IN (10, 2) AND "active" = TRUE AND "deleted" IS NULL AND "created" > '2020-01-02 03:04:05+00:00' AND "key" = '\x0aff' OR "id" = $9`
+	result := Interpolate("postgres",
+		`SELECT * FROM "users" WHERE "name" = $1 AND "note" = '$1' AND "id" IN ($3, $2) AND "active" = $4 AND "deleted" IS $5 AND "created" > $6 AND "key" = $7 OR "id" = $9`,
+		[]interface{}{"O'Neil", int8(2), uint(10), true, null, date, []byte{10, 255}},
+	)
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestInterpolate.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	expect = "SELECT * FROM `users?` WHERE `name` = 'a\\\\b''c' AND `note` = 'it\\'s ?' AND `rate` = 1.5 AND `active` = 0 AND `key` = X'0aff' AND `id` = ?"
+	result = Interpolate("mysql",
+		"SELECT * FROM `users?` WHERE `name` = ? AND `note` = 'it\\'s ?' AND `rate` = ? AND `active` = ? AND `key` = ? AND `id` = ?",
+		[]interface{}{`a\b'c`, 1.5, false, []byte{10, 255}},
+	)
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestInterpolate.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	expect = `SELECT * FROM "users" WHERE "name" = 'Jack' AND "created" > '2020-01-02 03:04:05'`
+	result = Interpolate("sqlite3", `SELECT * FROM "users" WHERE "name" = ? AND "created" > ?`, []interface{}{"Jack", date})
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestInterpolate.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
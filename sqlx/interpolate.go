@@ -0,0 +1,140 @@
+package sqlx
+
+import (
+	sqldriver "database/sql/driver"
+	"encoding/hex"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Строка запроса с подставленными вместо плейсхолдеров данными
+// Значения экранируются по правилам диалекта: $N для postgres, ? для mysql и sqlite3.
+// Плейсхолдеры внутри строк и идентификаторов в кавычках не заменяются.
+// Результат предназначен для журналов и отладки, для выполнения используйте плейсхолдеры.
+//
+//	sqlx.Interpolate("postgres", `SELECT * FROM "users" WHERE "name" = $1`, []interface{}{"O'Neil"})
+//	// SELECT * FROM "users" WHERE "name" = 'O''Neil'
+func Interpolate(dialect string, query string, args []interface{}) string {
+	dialect = dialectName(dialect)
+
+	var buf strings.Builder
+	buf.Grow(len(query))
+
+	var quote byte
+	next := 0
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		if quote != 0 {
+			buf.WriteByte(c)
+			if c == '\\' && quote == '\'' && dialect == "mysql" && i+1 < len(query) {
+				i++
+				buf.WriteByte(query[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '$' && dialect == "postgres":
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if n, err := strconv.Atoi(query[i+1 : j]); err == nil && n > 0 && n <= len(args) {
+				buf.WriteString(literal(dialect, args[n-1]))
+				i = j - 1
+				continue
+			}
+		case c == '?' && dialect != "postgres":
+			if next < len(args) {
+				buf.WriteString(literal(dialect, args[next]))
+				next++
+				continue
+			}
+		}
+
+		buf.WriteByte(c)
+	}
+
+	return buf.String()
+}
+
+// Значение в виде литерала SQL
+func literal(dialect string, v interface{}) string {
+	if valuer, ok := v.(sqldriver.Valuer); ok {
+		if value := reflect.ValueOf(v); value.Kind() == reflect.Ptr && value.IsNil() {
+			return "NULL"
+		}
+		value, err := valuer.Value()
+		if err != nil {
+			return "NULL"
+		}
+		v = value
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteString(dialect, x)
+	case []byte:
+		if dialect == "postgres" {
+			return `'\x` + hex.EncodeToString(x) + `'`
+		}
+		return "X'" + hex.EncodeToString(x) + "'"
+	case bool:
+		switch {
+		case dialect == "postgres" && x:
+			return "TRUE"
+		case dialect == "postgres":
+			return "FALSE"
+		case x:
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		if dialect == "postgres" {
+			return quoteString(dialect, x.Format("2006-01-02 15:04:05.999999-07:00"))
+		}
+		return quoteString(dialect, x.Format("2006-01-02 15:04:05.999999"))
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return "NULL"
+		}
+		return literal(dialect, value.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 64)
+	case reflect.Bool:
+		return literal(dialect, value.Bool())
+	case reflect.String:
+		return quoteString(dialect, value.String())
+	}
+
+	return quoteString(dialect, toString(v))
+}
+
+// Строка в одинарных кавычках, в MySQL экранируется и обратный слеш
+func quoteString(dialect string, s string) string {
+	s = strings.Replace(s, "'", "''", -1)
+	if dialect == "mysql" {
+		s = strings.Replace(s, `\`, `\\`, -1)
+	}
+	return "'" + s + "'"
+}
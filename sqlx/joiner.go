@@ -6,11 +6,15 @@ type joinCondition struct {
 	operator string
 	value    interface{}
 	boolean  string
+	list     List
+	joiner   *Joiner
 }
 
 type Joiner struct {
 	kind       string
 	table      string
+	builder    *Builder
+	lateral    bool
 	conditions []joinCondition
 	bindings   []interface{}
 }
@@ -42,6 +46,91 @@ func (self *Joiner) OrWhere(column string, operator string, value interface{}) *
 	return self
 }
 
+// Группа условий в скобках
+func (self *Joiner) OnGroup(callback func(*Joiner)) *Joiner {
+	self.group(callback, "AND")
+	return self
+}
+
+func (self *Joiner) OrOnGroup(callback func(*Joiner)) *Joiner {
+	self.group(callback, "OR")
+	return self
+}
+
+func (self *Joiner) WhereIn(column string, list List) *Joiner {
+	self.whereIn(column, list, "AND", false)
+	return self
+}
+
+func (self *Joiner) OrWhereIn(column string, list List) *Joiner {
+	self.whereIn(column, list, "OR", false)
+	return self
+}
+
+func (self *Joiner) WhereNotIn(column string, list List) *Joiner {
+	self.whereIn(column, list, "AND", true)
+	return self
+}
+
+func (self *Joiner) OrWhereNotIn(column string, list List) *Joiner {
+	self.whereIn(column, list, "OR", true)
+	return self
+}
+
+func (self *Joiner) WhereNull(column string) *Joiner {
+	self.whereNull(column, "AND", false)
+	return self
+}
+
+func (self *Joiner) OrWhereNull(column string) *Joiner {
+	self.whereNull(column, "OR", false)
+	return self
+}
+
+func (self *Joiner) WhereNotNull(column string) *Joiner {
+	self.whereNull(column, "AND", true)
+	return self
+}
+
+func (self *Joiner) OrWhereNotNull(column string) *Joiner {
+	self.whereNull(column, "OR", true)
+	return self
+}
+
+func (self *Joiner) whereIn(column string, list List, boolean string, not bool) {
+	kind := "in"
+	if not {
+		kind = "notin"
+	}
+	self.condition(joinCondition{kind: kind, column: column, list: list}, boolean)
+	self.bind(list...)
+}
+
+func (self *Joiner) whereNull(column string, boolean string, not bool) {
+	kind := "null"
+	if not {
+		kind = "notnull"
+	}
+	self.condition(joinCondition{kind: kind, column: column}, boolean)
+}
+
+func (self *Joiner) group(callback func(*Joiner), boolean string) {
+	joiner := newJoiner("", "")
+	callback(joiner)
+	if len(joiner.conditions) > 0 {
+		self.condition(joinCondition{kind: "group", joiner: joiner}, boolean)
+		self.bindings = append(self.bindings, joiner.bindings...)
+	}
+}
+
+func (self *Joiner) condition(c joinCondition, boolean string) {
+	if len(self.conditions) == 0 {
+		boolean = ""
+	}
+	c.boolean = boolean
+	self.conditions = append(self.conditions, c)
+}
+
 func (self *Joiner) where(kind, column, operator string, value interface{}, boolean string) {
 	if !isOperator(operator) {
 		panic("sqlx: such a \"operator\" is not allowed")
@@ -0,0 +1,190 @@
+package sqlx
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Соответствие колонок результата полям структуры
+//
+// Имя колонки задается тегом db:"name", db:"-" исключает поле.
+// Тег с завершающим подчеркиванием db:"author_" задает префикс
+// для колонок вложенной структуры: author_id -> Author.Id
+// Поля без тега сопоставляются через toCamel: user_name -> UserName
+// Поля внешней структуры имеют приоритет над полями встроенных.
+type fieldMap struct {
+	fields  []*field
+	columns map[string]*field
+	names   map[string]*field
+}
+
+type field struct {
+	index   []int
+	key     string
+	column  string
+	options []string
+	depth   int
+	// Поле вложенной структуры с префиксом, например из объединения
+	nested bool
+}
+
+// Опция из тега: db:"id,pk,auto"
+func (self *field) option(name string) bool {
+	for _, v := range self.options {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Карты строятся один раз для каждого типа
+var fieldMaps sync.Map
+
+func structFieldMap(t reflect.Type) *fieldMap {
+	if m, ok := fieldMaps.Load(t); ok {
+		return m.(*fieldMap)
+	}
+
+	m := &fieldMap{
+		columns: make(map[string]*field),
+		names:   make(map[string]*field),
+	}
+	candidates := m.build(t, nil, "", 0)
+
+	for _, f := range candidates {
+		if m.columns[f.key] == f || m.names[f.key] == f {
+			m.fields = append(m.fields, f)
+		}
+	}
+
+	actual, _ := fieldMaps.LoadOrStore(t, m)
+	return actual.(*fieldMap)
+}
+
+func (self *fieldMap) build(t reflect.Type, index []int, prefix string, depth int) []*field {
+	candidates := make([]*field, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		// Встроенная неэкспортируемая структура может содержать экспортируемые поля
+		if len(ft.PkgPath) > 0 && !(ft.Anonymous && ft.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		tag := strings.Split(ft.Tag.Get("db"), ",")
+		name := strings.TrimSpace(tag[0])
+		if name == "-" {
+			continue
+		}
+
+		idx := make([]int, len(index)+1)
+		copy(idx, index)
+		idx[len(index)] = i
+
+		if ft.Type.Kind() == reflect.Struct && (strings.HasSuffix(name, "_") || (ft.Anonymous && name == "")) {
+			candidates = append(candidates, self.build(ft.Type, idx, prefix+name, depth+1)...)
+			continue
+		}
+
+		f := &field{
+			index:   idx,
+			options: tag[1:],
+			depth:   depth,
+			nested:  prefix != "",
+		}
+
+		if name != "" {
+			f.column = prefix + name
+			f.key = f.column
+			self.add(self.columns, f.key, f)
+		} else {
+			f.column = prefix + toSnake(ft.Name)
+			f.key = toCamel(prefix) + ft.Name
+			self.add(self.names, f.key, f)
+		}
+
+		candidates = append(candidates, f)
+	}
+
+	return candidates
+}
+
+func (self *fieldMap) add(m map[string]*field, key string, f *field) {
+	if old, ok := m[key]; ok && old.depth <= f.depth {
+		return
+	}
+	m[key] = f
+}
+
+// Поле для колонки результата
+func (self *fieldMap) lookup(column string) (*field, bool) {
+	if f, ok := self.columns[column]; ok {
+		return f, true
+	}
+	f, ok := self.names[toCamel(column)]
+	return f, ok
+}
+
+// Первичный ключ: поле с опцией pk, иначе колонка id
+func (self *fieldMap) primary() *field {
+	for _, f := range self.fields {
+		if f.option("pk") && !f.nested {
+			return f
+		}
+	}
+	if f, ok := self.lookup("id"); ok && !f.nested {
+		return f
+	}
+	return nil
+}
+
+// Значение генерирует база данных: опция auto или целочисленная
+// колонка id без опций, не заполненная ни в одной из структур
+func (self *field) auto(values []reflect.Value) bool {
+	if self.option("auto") {
+		return true
+	}
+	if self.column != "id" || len(self.options) > 0 || len(values) == 0 {
+		return false
+	}
+	switch values[0].FieldByIndex(self.index).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return allZero(values, self.index)
+	}
+	return false
+}
+
+// Индексы полей в порядке колонок, nil - колонка без поля
+// В строгом режиме колонка без поля является ошибкой
+func (self *fieldMap) indexes(columns []string, strict bool) ([][]int, error) {
+	indexes := make([][]int, len(columns))
+	for k, column := range columns {
+		f, ok := self.lookup(column)
+		if !ok {
+			if strict {
+				return nil, fmt.Errorf("sqlx: missing destination field for column %s", column)
+			}
+			continue
+		}
+		indexes[k] = f.index
+	}
+	return indexes, nil
+}
+
+// Указатели на поля структуры для rows.Scan
+func pointers(v reflect.Value, indexes [][]int) []interface{} {
+	values := make([]interface{}, len(indexes))
+	for k, index := range indexes {
+		if index == nil {
+			values[k] = &sql.NullString{}
+			continue
+		}
+		values[k] = v.FieldByIndex(index).Addr().Interface()
+	}
+	return values
+}
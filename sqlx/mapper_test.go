@@ -0,0 +1,96 @@
+package sqlx
+
+import (
+	sqldriver "database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type mapperAuthor struct {
+	Id   int
+	Name string `db:"full_name"`
+}
+
+type mapperBase struct {
+	Id      int
+	Created string
+}
+
+type mapperPost struct {
+	mapperBase
+	Id       int `db:"post_id"`
+	Title    string
+	Secret   string       `db:"-"`
+	Author   mapperAuthor `db:"author_"`
+	UserName string
+}
+
+func TestFieldMap(t *testing.T) {
+	m := structFieldMap(reflect.TypeOf(mapperPost{}))
+
+	expect := map[string][]int{
+		"post_id":          {1},
+		"title":            {2},
+		"Title":            {2},
+		"author_id":        {4, 0},
+		"author_full_name": {4, 1},
+		"user_name":        {5},
+		"created":          {0, 1},
+		"id":               {0, 0},
+	}
+	for column, index := range expect {
+		f, ok := m.lookup(column)
+		if !ok || !reflect.DeepEqual(f.index, index) {
+			t.Errorf("Expect result to equal in func TestFieldMap.\nColumn: %s\nResult: %v\nExpect: %v", column, f, index)
+		}
+	}
+
+	for _, column := range []string{"secret", "author_name", "full_name"} {
+		if _, ok := m.lookup(column); ok {
+			t.Errorf("Expect column %s to be unmapped in func TestFieldMap", column)
+		}
+	}
+
+	if m != structFieldMap(reflect.TypeOf(mapperPost{})) {
+		t.Errorf("Expect cached field map in func TestFieldMap")
+	}
+}
+
+func TestScanTags(t *testing.T) {
+	db, _ := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		return fakeResponse{
+			columns: []string{"post_id", "title", "author_id", "author_full_name", "rating"},
+			rows: [][]sqldriver.Value{
+				{int64(1), "Hello", int64(5), "Jack", int64(10)},
+				{int64(2), "World", int64(6), "Mike", int64(20)},
+			},
+		}
+	})
+	defer db.Close()
+
+	dbx := DataBase(db)
+
+	posts := []mapperPost{}
+	if err := dbx.Query(Table("posts")).Scan(&posts); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 2 || posts[1].Id != 2 || posts[1].Title != "World" || posts[1].Author.Id != 6 || posts[1].Author.Name != "Mike" {
+		t.Errorf("Unexpected scan result in func TestScanTags: %+v", posts)
+	}
+
+	post := mapperPost{}
+	if err := dbx.Query(Table("posts")).Strict(true).Scan(&post); err == nil {
+		t.Errorf("Expect error for unmapped column in strict mode in func TestScanTags")
+	}
+
+	chunks := 0
+	err := dbx.Query(Table("posts")).Chunk(1, func(posts []mapperPost) {
+		if posts[0].Author.Name == "" {
+			t.Errorf("Unexpected chunk result in func TestScanTags: %+v", posts)
+		}
+		chunks++
+	})
+	if err != nil || chunks != 2 {
+		t.Errorf("Unexpected chunk result in func TestScanTags: %d, %v", chunks, err)
+	}
+}
@@ -0,0 +1,325 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// Таблица миграций по умолчанию
+const DefaultMigrationsTable = "migrations"
+
+// Миграция схемы
+// Миграции применяются в порядке возрастания имени,
+// поэтому имя удобно начинать с даты: 2024_01_15_create_users
+type Migration struct {
+	Name string
+	Up   func(ctx context.Context, tx *Tx) error
+	Down func(ctx context.Context, tx *Tx) error
+}
+
+// Состояние миграции, Batch - номер запуска, в котором она применена
+type MigrationStatus struct {
+	Name    string
+	Applied bool
+	Batch   int
+}
+
+// Запуск миграций
+//
+// Каждая миграция выполняется в своей транзакции вместе с записью в таблицу миграций.
+// Одновременный запуск из нескольких процессов блокируется: в PostgreSQL через
+// pg_advisory_lock, в MySQL через GET_LOCK. В SQLite блокировки нет: запись о миграции
+// делается до ее выполнения, поэтому второй запуск упрется в блокировку записи
+// или первичный ключ таблицы миграций и не выполнит миграцию повторно.
+// Одновременный запуск в SQLite не поддерживается и завершится ошибкой.
+//
+//	migrator := sqlx.NewMigrator(dbx, sqlx.Migration{
+//	    Name: "2024_01_15_create_users",
+//	    Up: func(ctx context.Context, tx *sqlx.Tx) error {
+//	        return tx.ExecSchema(ctx, sqlx.Schema.Create("users", func(t *sqlx.Blueprint) {
+//	            t.ID()
+//	            t.String("name", 255)
+//	        }))
+//	    },
+//	    Down: func(ctx context.Context, tx *sqlx.Tx) error {
+//	        return tx.ExecSchema(ctx, sqlx.Schema.Drop("users"))
+//	    },
+//	})
+//	applied, err := migrator.Up(ctx)
+type Migrator struct {
+	db         *DB
+	table      string
+	migrations []Migration
+}
+
+func NewMigrator(db *DB, migrations ...Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for k := 1; k < len(sorted); k++ {
+		if sorted[k].Name == sorted[k-1].Name {
+			panic("sqlx: duplicate migration '" + sorted[k].Name + "'")
+		}
+	}
+
+	return &Migrator{
+		db:         db,
+		table:      DefaultMigrationsTable,
+		migrations: sorted,
+	}
+}
+
+// Имя таблицы миграций
+func (self *Migrator) Table(name string) *Migrator {
+	self.table = name
+	return self
+}
+
+// Применение всех новых миграций одним запуском
+// Возвращает имена примененных миграций
+func (self *Migrator) Up(ctx context.Context) ([]string, error) {
+	applied := make([]string, 0)
+
+	err := self.session(ctx, func(conn *Conn) error {
+		records, err := self.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		batch := 1
+		for _, r := range records {
+			if r.Batch >= batch {
+				batch = r.Batch + 1
+			}
+		}
+
+		done := make(map[string]bool, len(records))
+		for _, r := range records {
+			done[r.Migration] = true
+		}
+
+		for _, m := range self.migrations {
+			if done[m.Name] {
+				continue
+			}
+			err := self.apply(ctx, conn, m.Up, Table(self.table).Insert(Data{"migration": m.Name, "batch": batch}))
+			if err != nil {
+				return fmt.Errorf("sqlx: migration %s: %s", m.Name, err)
+			}
+			applied = append(applied, m.Name)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Откат steps последних миграций, при steps <= 0 - последнего запуска
+// Возвращает имена откаченных миграций
+func (self *Migrator) Down(ctx context.Context, steps int) ([]string, error) {
+	reverted := make([]string, 0)
+
+	err := self.session(ctx, func(conn *Conn) error {
+		records, err := self.records(ctx, conn)
+		if err != nil || len(records) == 0 {
+			return err
+		}
+
+		sort.Slice(records, func(i, j int) bool {
+			if records[i].Batch != records[j].Batch {
+				return records[i].Batch > records[j].Batch
+			}
+			return records[i].Migration > records[j].Migration
+		})
+
+		for k, r := range records {
+			if steps > 0 && k == steps || steps <= 0 && r.Batch != records[0].Batch {
+				break
+			}
+
+			m, ok := self.migration(r.Migration)
+			if !ok {
+				return fmt.Errorf("sqlx: migration %s not found", r.Migration)
+			}
+			err := self.apply(ctx, conn, m.Down, Table(self.table).Where("migration", "=", m.Name).Delete())
+			if err != nil {
+				return fmt.Errorf("sqlx: migration %s: %s", m.Name, err)
+			}
+			reverted = append(reverted, m.Name)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Состояние известных и примененных миграций в порядке имен
+func (self *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	exists, err := self.exists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]migrationRecord, 0)
+	if exists {
+		err := self.db.Query(Table(self.table).Select("migration", "batch")).ScanContext(ctx, &records)
+		if err != nil && err != ErrNoRows {
+			return nil, err
+		}
+	}
+
+	batches := make(map[string]int, len(records))
+	for _, r := range records {
+		batches[r.Migration] = r.Batch
+	}
+
+	status := make([]MigrationStatus, 0, len(self.migrations))
+	for _, m := range self.migrations {
+		batch, ok := batches[m.Name]
+		status = append(status, MigrationStatus{Name: m.Name, Applied: ok, Batch: batch})
+		delete(batches, m.Name)
+	}
+	// Примененные миграции, которых нет в коде
+	for name, batch := range batches {
+		status = append(status, MigrationStatus{Name: name, Applied: true, Batch: batch})
+	}
+	sort.SliceStable(status, func(i, j int) bool {
+		return status[i].Name < status[j].Name
+	})
+
+	return status, nil
+}
+
+type migrationRecord struct {
+	Migration string
+	Batch     int
+}
+
+func (self *Migrator) migration(name string) (Migration, bool) {
+	for _, m := range self.migrations {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// Таблица миграций
+func (self *Migrator) schema() *Blueprint {
+	return Schema.CreateIfNotExists(self.table, func(t *Blueprint) {
+		t.String("migration", 255).Primary()
+		t.Integer("batch")
+		t.Timestamp("applied_at").Default(Raw("CURRENT_TIMESTAMP"))
+	})
+}
+
+// Наличие таблицы миграций, Status не создает ее
+func (self *Migrator) exists(ctx context.Context) (bool, error) {
+	var query *Query
+	switch self.db.Dialect() {
+	case "mysql":
+		query = self.db.QueryRaw("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", self.table)
+	case "sqlite3":
+		query = self.db.QueryRaw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", self.table)
+	default:
+		query = self.db.QueryRaw("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1", self.table)
+	}
+
+	count := 0
+	if err := query.WithContext(ctx).Value(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (self *Migrator) records(ctx context.Context, conn *Conn) ([]migrationRecord, error) {
+	records := make([]migrationRecord, 0)
+	err := conn.Query(Table(self.table).Select("migration", "batch")).ScanContext(ctx, &records)
+	if err != nil && err != ErrNoRows {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Запись о миграции и ее шаг в одной транзакции
+// Запись идет первой: без блокировки запуска (SQLite) конкурирующий запуск
+// остановится на ней и не выполнит шаг повторно
+func (self *Migrator) apply(ctx context.Context, conn *Conn, step func(context.Context, *Tx) error, record *Builder) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return runTx(tx, func(tx *Tx) error {
+		result, err := tx.Query(record).ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return errors.New("sqlx: migration record already changed by another run")
+		}
+		if step != nil {
+			return step(ctx, tx)
+		}
+		return nil
+	})
+}
+
+// Выделенное соединение с блокировкой от одновременного запуска
+func (self *Migrator) session(ctx context.Context, fn func(*Conn) error) error {
+	conn, err := self.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	unlock, err := self.lock(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := conn.ExecSchema(ctx, self.schema()); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// Блокировка на уровне сессии, снимается функцией unlock
+func (self *Migrator) lock(ctx context.Context, conn *Conn) (func(), error) {
+	name := "sqlx:" + self.table
+
+	switch self.db.Dialect() {
+	case "postgres":
+		hash := fnv.New64a()
+		hash.Write([]byte(name))
+		key := int64(hash.Sum64())
+		if _, err := conn.QueryRaw("SELECT pg_advisory_lock($1)", key).ExecContext(ctx); err != nil {
+			return nil, err
+		}
+		return func() {
+			conn.QueryRaw("SELECT pg_advisory_unlock($1)", key).ExecContext(context.Background())
+		}, nil
+	case "mysql":
+		locked := sql.NullInt64{}
+		if err := conn.QueryRaw("SELECT GET_LOCK(?, -1)", name).WithContext(ctx).Value(&locked); err != nil {
+			return nil, err
+		}
+		if locked.Int64 != 1 {
+			return nil, fmt.Errorf("sqlx: failed to acquire migrations lock %s", name)
+		}
+		return func() {
+			conn.QueryRaw("SELECT RELEASE_LOCK(?)", name).ExecContext(context.Background())
+		}, nil
+	}
+
+	return func() {}, nil
+}
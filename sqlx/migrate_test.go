@@ -0,0 +1,187 @@
+package sqlx
+
+import (
+	"context"
+	sqldriver "database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newMigratorDB(records [][]sqldriver.Value) (*DB, *fakeDB, func() error) {
+	db, fake := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		if query == `SELECT "migration", "batch" FROM "migrations"` {
+			return fakeResponse{columns: []string{"migration", "batch"}, rows: records}
+		}
+		if strings.Contains(query, "information_schema.tables") {
+			count := int64(0)
+			if records != nil {
+				count = 1
+			}
+			return fakeResponse{columns: []string{"count"}, rows: [][]sqldriver.Value{{count}}}
+		}
+		if strings.HasPrefix(query, "FAIL") {
+			return fakeResponse{err: errors.New("failed")}
+		}
+		return fakeResponse{affected: 1}
+	})
+	return DataBase(db), fake, db.Close
+}
+
+func testMigrations(calls *[]string) []Migration {
+	step := func(name string) func(context.Context, *Tx) error {
+		return func(ctx context.Context, tx *Tx) error {
+			*calls = append(*calls, name)
+			_, err := tx.QueryRaw(name).ExecContext(ctx)
+			return err
+		}
+	}
+	return []Migration{
+		{Name: "2024_01_02_b", Up: step("UP b"), Down: step("DOWN b")},
+		{Name: "2024_01_01_a", Up: step("UP a"), Down: step("DOWN a")},
+		{Name: "2024_01_03_c", Up: step("UP c"), Down: step("DOWN c")},
+	}
+}
+
+func TestMigratorUp(t *testing.T) {
+	db, fake, close := newMigratorDB([][]sqldriver.Value{{"2024_01_01_a", int64(1)}})
+	defer close()
+
+	calls := []string{}
+	applied, err := NewMigrator(db, testMigrations(&calls)...).Up(context.Background())
+	if err != nil || strings.Join(applied, ",") != "2024_01_02_b,2024_01_03_c" {
+		t.Fatalf("Unexpected migrator result in func TestMigratorUp: %v, %v", applied, err)
+	}
+
+	expect := []string{
+		"SELECT pg_advisory_lock($1)",
+		`CREATE TABLE IF NOT EXISTS "migrations" ( "migration" VARCHAR(255) NOT NULL, "batch" INTEGER NOT NULL, "applied_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY ( "migration" ) )`,
+		`SELECT "migration", "batch" FROM "migrations"`,
+		"BEGIN",
+		`INSERT INTO "migrations" ( "batch", "migration" ) VALUES ( $1, $2 )`,
+		"UP b",
+		"COMMIT",
+		"BEGIN",
+		`INSERT INTO "migrations" ( "batch", "migration" ) VALUES ( $1, $2 )`,
+		"UP c",
+		"COMMIT",
+		"SELECT pg_advisory_unlock($1)",
+	}
+	result := fake.queries()
+	if strings.Join(result, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("Expect result to equal in func TestMigratorUp.\nResult: %q\nExpect: %q", result, expect)
+	}
+	if args := fake.calls[4].args; !DataEqual(args, []interface{}{int64(2), "2024_01_02_b"}) {
+		t.Errorf("Expect batch 2 in func TestMigratorUp: %v", args)
+	}
+}
+
+func TestMigratorUpError(t *testing.T) {
+	db, fake, close := newMigratorDB(nil)
+	defer close()
+
+	migrations := []Migration{
+		{Name: "1_ok", Up: func(ctx context.Context, tx *Tx) error { return nil }},
+		{Name: "2_fail", Up: func(ctx context.Context, tx *Tx) error {
+			_, err := tx.QueryRaw("FAIL").ExecContext(ctx)
+			return err
+		}},
+		{Name: "3_skip"},
+	}
+	applied, err := NewMigrator(db, migrations...).Up(context.Background())
+	if err == nil || err.Error() != "sqlx: migration 2_fail: failed" || strings.Join(applied, ",") != "1_ok" {
+		t.Errorf("Unexpected migrator result in func TestMigratorUpError: %v, %v", applied, err)
+	}
+
+	result := fake.queries()
+	if result[len(result)-2] != "ROLLBACK" || result[len(result)-1] != "SELECT pg_advisory_unlock($1)" {
+		t.Errorf("Expect rollback and unlock in func TestMigratorUpError: %q", result)
+	}
+}
+
+func TestMigratorDown(t *testing.T) {
+	db, _, close := newMigratorDB([][]sqldriver.Value{
+		{"2024_01_01_a", int64(1)},
+		{"2024_01_02_b", int64(2)},
+		{"2024_01_03_c", int64(2)},
+	})
+	defer close()
+
+	calls := []string{}
+	reverted, err := NewMigrator(db, testMigrations(&calls)...).Down(context.Background(), 0)
+	if err != nil || strings.Join(reverted, ",") != "2024_01_03_c,2024_01_02_b" || strings.Join(calls, ",") != "DOWN c,DOWN b" {
+		t.Errorf("Unexpected migrator result in func TestMigratorDown: %v, %v, %v", reverted, calls, err)
+	}
+
+	calls = calls[:0]
+	reverted, err = NewMigrator(db, testMigrations(&calls)...).Down(context.Background(), 3)
+	if err != nil || strings.Join(reverted, ",") != "2024_01_03_c,2024_01_02_b,2024_01_01_a" {
+		t.Errorf("Unexpected migrator result in func TestMigratorDown: %v, %v", reverted, err)
+	}
+
+	_, err = NewMigrator(db).Down(context.Background(), 1)
+	if err == nil || err.Error() != "sqlx: migration 2024_01_03_c not found" {
+		t.Errorf("Expect not found error in func TestMigratorDown: %v", err)
+	}
+}
+
+func TestMigratorStatus(t *testing.T) {
+	db, _, close := newMigratorDB([][]sqldriver.Value{
+		{"2024_01_01_a", int64(1)},
+		{"2024_01_00_removed", int64(1)},
+	})
+	defer close()
+
+	calls := []string{}
+	status, err := NewMigrator(db, testMigrations(&calls)...).Status(context.Background())
+	expect := []MigrationStatus{
+		{Name: "2024_01_00_removed", Applied: true, Batch: 1},
+		{Name: "2024_01_01_a", Applied: true, Batch: 1},
+		{Name: "2024_01_02_b"},
+		{Name: "2024_01_03_c"},
+	}
+	if err != nil || len(status) != len(expect) {
+		t.Fatalf("Unexpected migrator result in func TestMigratorStatus: %v, %v", status, err)
+	}
+	for k := range expect {
+		if status[k] != expect[k] {
+			t.Errorf("Expect result to equal in func TestMigratorStatus.\nResult: %v\nExpect: %v", status[k], expect[k])
+		}
+	}
+}
+
+func TestMigratorStatusWithoutTable(t *testing.T) {
+	db, fake, close := newMigratorDB(nil)
+	defer close()
+
+	calls := []string{}
+	status, err := NewMigrator(db, testMigrations(&calls)...).Status(context.Background())
+	if err != nil || len(status) != 3 || status[0] != (MigrationStatus{Name: "2024_01_01_a"}) {
+		t.Fatalf("Unexpected migrator result in func TestMigratorStatusWithoutTable: %v, %v", status, err)
+	}
+
+	expect := []string{"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"}
+	result := fake.queries()
+	if strings.Join(result, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("Expect result to equal in func TestMigratorStatusWithoutTable.\nResult: %q\nExpect: %q", result, expect)
+	}
+}
+
+func TestMigratorDownAlreadyReverted(t *testing.T) {
+	db, fake := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		if query == `SELECT "migration", "batch" FROM "migrations"` {
+			return fakeResponse{columns: []string{"migration", "batch"}, rows: [][]sqldriver.Value{{"2024_01_01_a", int64(1)}}}
+		}
+		return fakeResponse{}
+	})
+	defer db.Close()
+
+	calls := []string{}
+	_, err := NewMigrator(DataBase(db), testMigrations(&calls)...).Down(context.Background(), 0)
+	if err == nil || len(calls) != 0 {
+		t.Errorf("Expect error without down step in func TestMigratorDownAlreadyReverted: %v, %v", calls, err)
+	}
+	if result := fake.queries(); result[len(result)-2] != "ROLLBACK" {
+		t.Errorf("Expect rollback in func TestMigratorDownAlreadyReverted: %q", result)
+	}
+}
@@ -0,0 +1,75 @@
+package sqlx
+
+import (
+	"errors"
+
+	"github.com/AlexanderGrom/componenta/page"
+)
+
+// Постраничная выборка в срез dest
+//
+// Количество строк считается отдельным запросом без сортировки и лимитов,
+// запросы с группировкой, DISTINCT или объединениями оборачиваются в подзапрос.
+// Возвращает параметры для page.New, пустая страница не считается ошибкой.
+//
+//	users := []User{}
+//	param, err := dbx.Query(sqlx.Table("users").OrderBy("id", "desc")).Paginate(num, 20, &users)
+//	param.ViewPage, param.CurrentURI = 5, r.URL.RequestURI()
+//	result := page.New(param).Query("page")
+func (self *Query) Paginate(pageNum, perPage int, dest interface{}) (*page.Param, error) {
+	if self.builder == nil || self.builder.kind != "" && self.builder.kind != "select" {
+		return nil, errors.New("sqlx: paginate requires a select query builder")
+	}
+	if perPage < 1 {
+		return nil, errors.New("sqlx: paginate requires positive per page")
+	}
+	if pageNum < 1 {
+		pageNum = 1
+	}
+
+	param := &page.Param{
+		ViewItem:    perPage,
+		CurrentPage: pageNum,
+	}
+
+	total := 0
+	if err := self.derive(self.builder.countBuilder()).Value(&total); err != nil {
+		return nil, err
+	}
+	param.TotalItem = total
+
+	if total == 0 {
+		return param, nil
+	}
+
+	// Собственные лимит и смещение строителя заменяются страницей
+	list := self.builder.Clone().ClearLimit().ClearOffset().Limit(perPage).Offset((pageNum - 1) * perPage)
+	if err := self.derive(list).Scan(dest); err != nil && err != ErrNoRows {
+		return nil, err
+	}
+
+	return param, nil
+}
+
+// Запрос на том же подключении и с теми же настройками
+func (self *Query) derive(builder *Builder) *Query {
+	query := newQuery(self.db, self.dialect, builder)
+	query.ctx, query.stmts, query.tx = self.ctx, self.stmts, self.tx
+	query.hooks, query.strict = self.hooks, self.strict
+	return query
+}
+
+// Запрос количества строк без сортировки и лимитов
+func (self *Builder) countBuilder() *Builder {
+	count := self.Clone().ClearOrder().ClearLimit().ClearOffset()
+	c := count.components
+
+	if len(c.Group) > 0 || len(c.Having) > 0 || len(c.Distinct) > 0 || len(c.Union) > 0 {
+		if count.table == "" {
+			count.table = "sqlx_count"
+		}
+		return NewBuilder().From(count).Count("*", "aggregate")
+	}
+
+	return count.ClearSelect().Count("*", "aggregate")
+}
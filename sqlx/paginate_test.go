@@ -0,0 +1,72 @@
+package sqlx
+
+import (
+	sqldriver "database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestQueryPaginate(t *testing.T) {
+	total := int64(5)
+	db, fake := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		if strings.HasPrefix(query, "SELECT COUNT(*)") {
+			return fakeResponse{columns: []string{"aggregate"}, rows: [][]sqldriver.Value{{total}}}
+		}
+		return fakeResponse{
+			columns: []string{"id", "name"},
+			rows:    [][]sqldriver.Value{{int64(3), "Jack"}, {int64(2), "Mike"}},
+		}
+	})
+	defer db.Close()
+	dbx := DataBase(db)
+
+	builder := Table("users").Where("age", ">", 18).OrderBy("id", "desc")
+	users := []map[string]interface{}{}
+	param, err := dbx.Query(builder).Paginate(2, 2, &users)
+	if err != nil || param.TotalItem != 5 || param.ViewItem != 2 || param.CurrentPage != 2 || len(users) != 2 {
+		t.Fatalf("Unexpected paginate result in func TestQueryPaginate: %+v, %v, %v", param, users, err)
+	}
+
+	expect := []string{
+		`SELECT COUNT(*) as "aggregate" FROM "users" WHERE "age" > $1`,
+		`SELECT * FROM "users" WHERE "age" > $1 ORDER BY "id" desc LIMIT $2 OFFSET $3`,
+	}
+	if result := fake.queries(); strings.Join(result, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("Expect result to equal in func TestQueryPaginate.\nResult: %q\nExpect: %q", result, expect)
+	}
+	if args := fake.calls[1].args; !DataEqual(args, []interface{}{int64(18), int64(2), int64(2)}) {
+		t.Errorf("Unexpected paginate data in func TestQueryPaginate: %v", args)
+	}
+	if result := builder.Sql(); result != `SELECT * FROM "users" WHERE "age" > $1 ORDER BY "id" desc` {
+		t.Errorf("Expect builder to be unchanged in func TestQueryPaginate: %s", result)
+	}
+
+	_, err = dbx.Query(Table("users").Where("age", ">", 18).Limit(100).Offset(7)).Paginate(2, 2, &users)
+	if err != nil {
+		t.Fatalf("Unexpected paginate error in func TestQueryPaginate: %v", err)
+	}
+	expect = []string{
+		`SELECT COUNT(*) as "aggregate" FROM "users" WHERE "age" > $1`,
+		`SELECT * FROM "users" WHERE "age" > $1 LIMIT $2 OFFSET $3`,
+	}
+	if result := fake.queries()[2:]; strings.Join(result, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("Expect result to equal in func TestQueryPaginate.\nResult: %q\nExpect: %q", result, expect)
+	}
+	if args := fake.calls[3].args; !DataEqual(args, []interface{}{int64(18), int64(2), int64(2)}) {
+		t.Errorf("Unexpected paginate data with builder limit in func TestQueryPaginate: %v", args)
+	}
+
+	expect = []string{`SELECT COUNT(*) as "aggregate" FROM ( SELECT "age" FROM "users" GROUP BY "age" ) as "users"`}
+	total = 0
+	param, err = dbx.Query(Table("users").Select("age").GroupBy("age").OrderBy("age", "asc")).Paginate(1, 10, &users)
+	if err != nil || param.TotalItem != 0 {
+		t.Errorf("Unexpected paginate result in func TestQueryPaginate: %+v, %v", param, err)
+	}
+	if result := fake.queries()[4:]; strings.Join(result, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("Expect result to equal in func TestQueryPaginate.\nResult: %q\nExpect: %q", result, expect)
+	}
+
+	if _, err := dbx.QueryRaw("SELECT * FROM users").Paginate(1, 10, &users); err == nil {
+		t.Errorf("Expect error for raw query in func TestQueryPaginate")
+	}
+}
@@ -1,27 +1,53 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
 )
 
 type Querier interface {
 	Query(*Builder) *Query
 }
 
+// Общий интерфейс *sql.DB, *sql.Tx и *sql.Conn
 type DataBaser interface {
-	Query(string, ...interface{}) (*sql.Rows, error)
-	Exec(string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
 }
 
 type DB struct {
-	db *sql.DB
+	db       *sql.DB
+	dialect  string
+	stmts    *stmtCache
+	hooks    []Hook
+	attempts int
+	backoff  time.Duration
 }
 
 // Helper для добавления нового подключения
-func DataBase(db *sql.DB) *DB {
-	return &DB{
-		db: db,
+// Диалект подключения: mysql, postgres, sqlite3
+// Если не указан, используется драйвер заданный через sqlx.Driver
+func DataBase(db *sql.DB, dialect ...string) *DB {
+	self := &DB{
+		db:       db,
+		attempts: DefaultTxAttempts,
+		backoff:  DefaultTxBackoff,
 	}
+	if len(dialect) > 0 {
+		lookupDriver(dialect[0])
+		self.dialect = dialect[0]
+	}
+	return self
+}
+
+// Диалект подключения
+func (self *DB) Dialect() string {
+	return dialectName(self.dialect)
 }
 
 func (self *DB) Origin() *sql.DB {
@@ -29,32 +55,107 @@ func (self *DB) Origin() *sql.DB {
 }
 
 func (self *DB) Query(builder *Builder) *Query {
-	return &Query{
-		db:    self.db,
-		query: builder.Sql(),
-		data:  builder.Data(),
-	}
+	query := newQuery(self.db, self.dialect, builder)
+	query.stmts, query.hooks = self.stmts, self.hooks
+	return query
 }
 
 func (self *DB) QueryRaw(query string, data ...interface{}) *Query {
-	return &Query{
-		db:    self.db,
-		query: query,
-		data:  data,
+	q := newQueryRaw(self.db, self.dialect, query, data)
+	q.stmts, q.hooks = self.stmts, self.hooks
+	return q
+}
+
+// Включает кеш подготовленных выражений на size запросов
+// Запросы с одинаковой строкой SQL используют одно подготовленное выражение,
+// в транзакциях оно переподготавливается через Tx.Stmt
+// Нулевой size выключает кеш и закрывает выражения
+func (self *DB) StmtCache(size int) *DB {
+	if self.stmts != nil {
+		self.stmts.close()
+		self.stmts = nil
 	}
+	if size > 0 {
+		self.stmts = newStmtCache(self.db, size)
+	}
+	return self
+}
+
+// Статистика кеша подготовленных выражений
+func (self *DB) CacheStats() CacheStats {
+	if self.stmts == nil {
+		return CacheStats{}
+	}
+	return self.stmts.statistics()
 }
 
 // Начать транзакцию
 func (self *DB) Begin() (*Tx, error) {
-	tx, err := self.db.Begin()
+	return self.BeginTx(context.Background(), nil)
+}
+
+// Начать транзакцию с контекстом и параметрами изоляции
+func (self *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := self.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, dialect: self.dialect, stmts: self.stmts, hooks: self.hooks}, err
+}
+
+// Выделенное соединение из пула
+// Соединение необходимо вернуть в пул вызовом Close
+func (self *DB) Conn(ctx context.Context) (*Conn, error) {
+	conn, err := self.db.Conn(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &Tx{tx}, err
+	return &Conn{conn: conn, dialect: self.dialect, hooks: self.hooks}, nil
+}
+
+// Начать транзакцию на выделенном соединении
+func (self *Conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := self.conn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, dialect: self.dialect, hooks: self.hooks}, nil
+}
+
+type Conn struct {
+	conn    *sql.Conn
+	dialect string
+	hooks   []Hook
+}
+
+func (self *Conn) Origin() *sql.Conn {
+	return self.conn
+}
+
+func (self *Conn) Query(builder *Builder) *Query {
+	query := newQuery(self.conn, self.dialect, builder)
+	query.hooks = self.hooks
+	return query
+}
+
+func (self *Conn) QueryRaw(query string, data ...interface{}) *Query {
+	q := newQueryRaw(self.conn, self.dialect, query, data)
+	q.hooks = self.hooks
+	return q
+}
+
+// Вернуть соединение в пул
+func (self *Conn) Close() error {
+	return self.conn.Close()
 }
 
 type Tx struct {
-	tx *sql.Tx
+	tx      *sql.Tx
+	dialect string
+	stmts   *stmtCache
+	hooks   []Hook
+	// Глубина вложенных транзакций для имен SAVEPOINT
+	savepoints int
 }
 
 func (self *Tx) Origin() *sql.Tx {
@@ -62,19 +163,15 @@ func (self *Tx) Origin() *sql.Tx {
 }
 
 func (self *Tx) Query(builder *Builder) *Query {
-	return &Query{
-		db:    self.tx,
-		query: builder.Sql(),
-		data:  builder.Data(),
-	}
+	query := newQuery(self.tx, self.dialect, builder)
+	query.stmts, query.tx, query.hooks = self.stmts, self.tx, self.hooks
+	return query
 }
 
 func (self *Tx) QueryRaw(query string, data ...interface{}) *Query {
-	return &Query{
-		db:    self.tx,
-		query: query,
-		data:  data,
-	}
+	q := newQueryRaw(self.tx, self.dialect, query, data)
+	q.stmts, q.tx, q.hooks = self.stmts, self.tx, self.hooks
+	return q
 }
 
 // Зафиксировать транзакцию
@@ -110,31 +207,390 @@ func (self customResult) RowsAffected() int64 {
 }
 
 type Query struct {
-	db    DataBaser
-	query string
-	data  []interface{}
+	db      DataBaser
+	ctx     context.Context
+	dialect string
+	query   string
+	data    []interface{}
+	stmts   *stmtCache
+	tx      *sql.Tx
+	strict  bool
+	hooks   []Hook
+	// Эмуляция RETURNING для MySQL
+	returning []interface{}
+	inserts   int
+	// Структуры для заполнения первичного ключа после вставки
+	structs []reflect.Value
+	primary *field
+	cursor  *cursorState
+	// Строитель запроса для Paginate
+	builder *Builder
+	// Ошибка построения, возвращается при выполнении
+	err error
+}
+
+func newQuery(db DataBaser, dialect string, builder *Builder) *Query {
+	query := &Query{
+		db:      db,
+		dialect: dialectName(dialect),
+		data:    builder.data(dialect),
+	}
+	query.query, query.err = builder.compile(dialect)
+	if query.dialect == "mysql" && len(builder.components.Returning) > 0 {
+		// MySQL не поддерживает RETURNING, эмулируется только для Insert
+		if builder.kind != "insert" && query.err == nil {
+			query.err = fmt.Errorf("sqlx: mysql does not support returning for %s", builder.kind)
+		}
+		query.returning = builder.components.Returning
+		query.inserts = len(builder.components.Values)
+	}
+	query.cursor, query.builder = builder.cursor, builder
+	if builder.kind == "insert" && len(builder.structs) > 0 {
+		query.structs = builder.structs
+		query.primary = builder.primary
+	}
+	return query
+}
+
+func newQueryRaw(db DataBaser, dialect string, query string, data []interface{}) *Query {
+	return &Query{
+		db:      db,
+		dialect: dialectName(dialect),
+		query:   query,
+		data:    data,
+	}
+}
+
+// Контекст, который будет использован методами Exec, Scan и Chunk
+func (self *Query) WithContext(ctx context.Context) *Query {
+	self.ctx = ctx
+	return self
+}
+
+func (self *Query) context() context.Context {
+	if self.ctx == nil {
+		return context.Background()
+	}
+	return self.ctx
+}
+
+// Строгий режим сканирования в структуры:
+// колонка без соответствующего поля является ошибкой
+func (self *Query) Strict(enable bool) *Query {
+	self.strict = enable
+	return self
 }
 
 // Выполнение запроса
 func (self *Query) Exec() (Result, error) {
-	res, err := self.db.Exec(self.query, self.data...)
+	return self.ExecContext(self.context())
+}
+
+// Выполнение запроса с контекстом
+func (self *Query) ExecContext(ctx context.Context) (Result, error) {
+	if self.structs != nil {
+		return self.execStructs(ctx)
+	}
+	res, err := self.exec(ctx)
 	return customResult{res}, err
 }
 
 // Сканировать результаты
 func (self *Query) Scan(a ...interface{}) error {
-	rows, err := self.db.Query(self.query, self.data...)
+	return self.ScanContext(self.context(), a...)
+}
+
+// Сканировать результаты с контекстом
+func (self *Query) ScanContext(ctx context.Context, a ...interface{}) error {
+	if self.err != nil {
+		return self.err
+	}
+	if self.returning != nil {
+		return self.scanInsertId(ctx, a...)
+	}
+	rows, err := self.rows(ctx)
+	if err != nil {
+		return err
+	}
+	return NewScanner(rows).Strict(self.strict).Scan(a...)
+}
+
+// Построчное чтение результата, Rows необходимо закрыть
+func (self *Query) Rows() (*Rows, error) {
+	return self.RowsContext(self.context())
+}
+
+// Построчное чтение результата с контекстом
+func (self *Query) RowsContext(ctx context.Context) (*Rows, error) {
+	rows, err := self.rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(rows, self.strict), nil
+}
+
+// Значения одной колонки в срез: Pluck("id", &ids)
+func (self *Query) Pluck(column string, dest interface{}) error {
+	rows, err := self.rows(self.context())
+	if err != nil {
+		return err
+	}
+	scanner := NewScanner(rows)
+	defer rows.Close()
+	return scanner.scanColumn(dest, column)
+}
+
+// Значение первой колонки первой строки: COUNT, MAX и т.п.
+func (self *Query) Value(dest interface{}) error {
+	rows, err := self.rows(self.context())
 	if err != nil {
 		return err
 	}
-	return NewScanner(rows).Scan(a...)
+	scanner := NewScanner(rows)
+	defer rows.Close()
+	return scanner.scanValue(dest)
+}
+
+// Есть ли строки в результате: SELECT EXISTS( ... )
+func (self *Query) Exists() (bool, error) {
+	query := *self
+	query.query = "SELECT EXISTS( " + self.query + " )"
+	query.returning, query.structs = nil, nil
+
+	var exists bool
+	if err := query.Value(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
 }
 
 // Сканировать в "чанки" и обрабатывать по кускам
 func (self *Query) Chunk(i int, f ChunkFunk) error {
-	rows, err := self.db.Query(self.query, self.data...)
+	return self.ChunkContext(self.context(), i, f)
+}
+
+// Сканировать в "чанки" с контекстом
+func (self *Query) ChunkContext(ctx context.Context, i int, f ChunkFunk) error {
+	if i < 1 {
+		return errChunkSize
+	}
+	rows, err := self.rows(ctx)
+	if err != nil {
+		return err
+	}
+	return NewChunker(rows).Strict(self.strict).Chunk(i, f)
+}
+
+// Выполнение запроса с вызовом хуков
+func (self *Query) exec(ctx context.Context) (sql.Result, error) {
+	if self.err != nil {
+		return nil, self.err
+	}
+	ctx, event := self.before(ctx)
+	res, err := self.execStmt(ctx)
+	if event != nil {
+		if err == nil {
+			event.RowsAffected, _ = res.RowsAffected()
+		}
+		self.after(ctx, event, err)
+	}
+	return res, err
+}
+
+func (self *Query) rows(ctx context.Context) (*sql.Rows, error) {
+	if self.err != nil {
+		return nil, self.err
+	}
+	ctx, event := self.before(ctx)
+	rows, err := self.queryStmt(ctx)
+	if event != nil {
+		self.after(ctx, event, err)
+	}
+	return rows, err
+}
+
+// Выполнение запроса через кеш подготовленных выражений, если он включен
+func (self *Query) execStmt(ctx context.Context) (sql.Result, error) {
+	if self.stmts == nil {
+		return self.db.ExecContext(ctx, self.query, self.data...)
+	}
+	entry, err := self.stmts.get(ctx, self.query)
+	if err != nil {
+		return nil, err
+	}
+	defer self.stmts.release(entry)
+	return self.stmt(ctx, entry.stmt).ExecContext(ctx, self.data...)
+}
+
+func (self *Query) queryStmt(ctx context.Context) (*sql.Rows, error) {
+	if self.stmts == nil {
+		return self.db.QueryContext(ctx, self.query, self.data...)
+	}
+	entry, err := self.stmts.get(ctx, self.query)
+	if err != nil {
+		return nil, err
+	}
+	// Открытые строки удерживают выражение сами, его можно освободить сразу
+	defer self.stmts.release(entry)
+	return self.stmt(ctx, entry.stmt).QueryContext(ctx, self.data...)
+}
+
+// Выражение из кеша, в транзакции - привязанная к ней копия,
+// которая будет закрыта вместе с транзакцией
+func (self *Query) stmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	if self.tx != nil {
+		return self.tx.StmtContext(ctx, stmt)
+	}
+	return stmt
+}
+
+// Результат вставки структур через RETURNING
+type insertResult struct {
+	lastId   int64
+	affected int64
+}
+
+func (self insertResult) LastInsertId() int64 {
+	return self.lastId
+}
+
+func (self insertResult) RowsAffected() int64 {
+	return self.affected
+}
+
+// Вставка структур с заполнением первичного ключа
+func (self *Query) execStructs(ctx context.Context) (Result, error) {
+	if self.dialect == "mysql" {
+		res, err := self.exec(ctx)
+		if err != nil {
+			return insertResult{}, err
+		}
+		result := customResult{res}
+		// LastInsertId надежен только для одной строки
+		if len(self.structs) == 1 && self.structs[0].IsValid() {
+			if id := result.LastInsertId(); id > 0 {
+				if err := setInsertId(self.structs[0].FieldByIndex(self.primary.index), id); err != nil {
+					return result, err
+				}
+			}
+		}
+		return result, nil
+	}
+
+	rows, err := self.rows(ctx)
+	if err != nil {
+		return insertResult{}, err
+	}
+	defer rows.Close()
+
+	// Все колонки RETURNING заполняют поля структуры, не только первичный ключ
+	columns, err := rows.Columns()
+	if err != nil {
+		return insertResult{}, fmt.Errorf("sqlx: %s", err)
+	}
+	var indexes [][]int
+	for _, s := range self.structs {
+		if s.IsValid() {
+			if indexes, err = structFieldMap(s.Type()).indexes(columns, self.strict); err != nil {
+				return insertResult{}, err
+			}
+			break
+		}
+	}
+
+	result := insertResult{}
+	for ; rows.Next(); result.affected++ {
+		var dest []interface{}
+		var target reflect.Value
+		if int(result.affected) < len(self.structs) && self.structs[result.affected].IsValid() {
+			target = self.structs[result.affected]
+			dest = pointers(target, indexes)
+		} else {
+			dest = make([]interface{}, len(columns))
+			for k := range dest {
+				dest[k] = new(interface{})
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return result, fmt.Errorf("sqlx: %s", err)
+		}
+		if target.IsValid() {
+			pk := target.FieldByIndex(self.primary.index)
+			switch pk.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				result.lastId = pk.Int()
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("sqlx: %s", err)
+	}
+
+	return result, rows.Close()
+}
+
+// Эмуляция RETURNING для MySQL: значение автоинкрементной колонки
+// вставленной строки берется из LastInsertId
+func (self *Query) scanInsertId(ctx context.Context, a ...interface{}) error {
+	if len(self.returning) != 1 || self.inserts != 1 {
+		return errors.New("sqlx: mysql returning supports only one auto increment column of a single row insert")
+	}
+	if len(a) == 0 {
+		return errors.New("sqlx: no destination")
+	}
+
+	dest := reflect.ValueOf(a[0])
+	if dest.Kind() != reflect.Ptr {
+		return errors.New("sqlx: destination not a pointe")
+	}
+	if dest.IsNil() {
+		return errors.New("sqlx: destination pointer is nil")
+	}
+
+	target := dest.Elem()
+	if target.Kind() == reflect.Struct {
+		column := toString(self.returning[0])
+		column = column[strings.LastIndex(column, ".")+1:]
+		field, ok := structFieldMap(target.Type()).lookup(column)
+		if !ok {
+			return fmt.Errorf("sqlx: missing destination field for column %s", column)
+		}
+		target = target.FieldByIndex(field.index)
+	}
+
+	res, err := self.exec(ctx)
 	if err != nil {
 		return err
 	}
-	return NewChunker(rows).Chunk(i, f)
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+	// Строка не вставлена, например при INSERT IGNORE
+	if id == 0 {
+		return ErrNoRows
+	}
+
+	return setInsertId(target, id)
+}
+
+// Запись LastInsertId в поле или переменную
+func setInsertId(target reflect.Value, id int64) error {
+	if scanner, ok := target.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(id)
+	}
+
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		target.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		target.SetUint(uint64(id))
+	case reflect.Interface:
+		target.Set(reflect.ValueOf(id))
+	default:
+		return fmt.Errorf("sqlx: unsupported destination type %s for insert id", target.Type())
+	}
+
+	return nil
 }
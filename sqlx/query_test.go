@@ -0,0 +1,159 @@
+package sqlx
+
+import (
+	"context"
+	sqldriver "database/sql/driver"
+	"testing"
+)
+
+func TestQueryContext(t *testing.T) {
+	db, fake := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		return fakeResponse{
+			columns: []string{"id", "name"},
+			rows:    [][]sqldriver.Value{{int64(1), "Jack"}},
+		}
+	})
+	defer db.Close()
+
+	dbx := DataBase(db)
+
+	var id int
+	var name string
+	err := dbx.Query(Table("users").Select("id", "name")).WithContext(context.Background()).Scan(&id, &name)
+	if err != nil || id != 1 || name != "Jack" {
+		t.Errorf("Unexpected scan result in func TestQueryContext: %v, %v, %v", id, name, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := dbx.Query(Table("users").Delete()).ExecContext(ctx); err != context.Canceled {
+		t.Errorf("Expect context.Canceled in func TestQueryContext, got: %v", err)
+	}
+	if err := dbx.Query(Table("users")).ScanContext(ctx, &id, &name); err != context.Canceled {
+		t.Errorf("Expect context.Canceled in func TestQueryContext, got: %v", err)
+	}
+	if err := dbx.Query(Table("users")).Chunk(0, func([]struct{}) {}); err != errChunkSize {
+		t.Errorf("Expect chunk size error in func TestQueryContext, got: %v", err)
+	}
+	if err := dbx.Query(Table("users")).WithContext(ctx).Chunk(10, func([]struct{}) {}); err != context.Canceled {
+		t.Errorf("Expect context.Canceled in func TestQueryContext, got: %v", err)
+	}
+
+	if n := len(fake.queries()); n != 1 {
+		t.Errorf("Expect 1 query in func TestQueryContext, got: %d", n)
+	}
+}
+
+func TestConnQuery(t *testing.T) {
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	conn, err := DataBase(db).Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Query(Table("users").Where("id", "=", 1).Delete()).Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `DELETE FROM "users" WHERE "id" = $1`
+	if q := fake.queries(); len(q) != 1 || q[0] != expect {
+		t.Errorf("Expect result to equal in func TestConnQuery.\nResult: %v\nExpect: %s", q, expect)
+	}
+}
+
+func TestQueryDialect(t *testing.T) {
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	mysql := DataBase(db, "mysql")
+	pgsql := DataBase(db)
+
+	mysql.Query(Table("users").Where("id", "=", 1).Delete()).Exec()
+	pgsql.Query(Table("users").Where("id", "=", 1).Delete()).Exec()
+
+	tx, err := mysql.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Query(Table("users").Where("id", "=", 2).Delete()).Exec()
+	tx.Commit()
+
+	expect := []string{
+		"DELETE FROM `users` WHERE `id` = ?",
+		`DELETE FROM "users" WHERE "id" = $1`,
+		"BEGIN",
+		"DELETE FROM `users` WHERE `id` = ?",
+		"COMMIT",
+	}
+	result := fake.queries()
+	if len(result) != len(expect) {
+		t.Fatalf("Expect result to equal in func TestQueryDialect.\nResult: %q\nExpect: %q", result, expect)
+	}
+	for k := range expect {
+		if result[k] != expect[k] {
+			t.Errorf("Expect result to equal in func TestQueryDialect.\nResult: %s\nExpect: %s", result[k], expect[k])
+		}
+	}
+
+	if mysql.Dialect() != "mysql" || pgsql.Dialect() != "postgres" {
+		t.Errorf("Unexpected dialects in func TestQueryDialect: %s, %s", mysql.Dialect(), pgsql.Dialect())
+	}
+}
+
+func TestQueryReturning(t *testing.T) {
+	db, fake := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		return fakeResponse{
+			columns: []string{"id", "name"},
+			rows:    [][]sqldriver.Value{{int64(7), "Jack"}},
+			lastId:  12,
+		}
+	})
+	defer db.Close()
+
+	type User struct {
+		Id   int
+		Name string
+	}
+
+	user := User{}
+	err := DataBase(db).Query(Table("users").Where("id", "=", 7).Update(Data{"name": "Jack"}).Returning("id", "name")).Scan(&user)
+	if err != nil || user.Id != 7 || user.Name != "Jack" {
+		t.Errorf("Unexpected scan result in func TestQueryReturning: %v, %v", user, err)
+	}
+
+	user = User{Name: "Jack"}
+	err = DataBase(db, "mysql").Query(Table("users").Insert(Data{"name": "Jack"}).ReturnId()).Scan(&user)
+	if err != nil || user.Id != 12 {
+		t.Errorf("Unexpected scan result in func TestQueryReturning: %v, %v", user, err)
+	}
+
+	var id int64
+	err = DataBase(db, "mysql").Query(Table("users").Insert(Data{"name": "Jack"}).Returning("users.id")).Scan(&id)
+	if err != nil || id != 12 {
+		t.Errorf("Unexpected scan result in func TestQueryReturning: %v, %v", id, err)
+	}
+
+	err = DataBase(db, "mysql").Query(Table("users").Insert(Data{"name": "Jack"}, Data{"name": "Mike"}).ReturnId()).Scan(&id)
+	if err == nil {
+		t.Errorf("Expect error for multi-row insert in func TestQueryReturning")
+	}
+
+	err = DataBase(db, "mysql").Query(Table("users").Where("id", "=", 7).Update(Data{"name": "Jack"}).Returning("id")).Scan(&id)
+	if err == nil {
+		t.Errorf("Expect error for mysql update returning in func TestQueryReturning")
+	}
+
+	_, err = DataBase(db, "mysql").Query(Table("users").Where("id", "=", 7).Delete().Returning("id")).Exec()
+	if err == nil {
+		t.Errorf("Expect error for mysql delete returning in func TestQueryReturning")
+	}
+
+	expect := "INSERT INTO `users` ( `name` ) VALUES ( ? )"
+	if q := fake.queries(); len(q) != 3 || q[2] != expect {
+		t.Errorf("Expect result to equal in func TestQueryReturning.\nResult: %q\nExpect: %s", q, expect)
+	}
+}
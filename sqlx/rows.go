@@ -0,0 +1,210 @@
+package sqlx
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// Построчное чтение результата без загрузки всех строк в память
+//
+//	rows, err := db.Query(sqlx.Table("users")).Rows()
+//	if err != nil {
+//	    return err
+//	}
+//	defer rows.Close()
+//
+//	for rows.Next() {
+//	    user := User{}
+//	    if err := rows.ScanStruct(&user); err != nil {
+//	        return err
+//	    }
+//	}
+//	return rows.Err()
+type Rows struct {
+	rows    *sql.Rows
+	strict  bool
+	columns []string
+	indexes map[reflect.Type][][]int
+}
+
+func newRows(rows *sql.Rows, strict bool) *Rows {
+	return &Rows{
+		rows:    rows,
+		strict:  strict,
+		indexes: make(map[reflect.Type][][]int),
+	}
+}
+
+func (self *Rows) Origin() *sql.Rows {
+	return self.rows
+}
+
+// Переход к следующей строке
+func (self *Rows) Next() bool {
+	return self.rows.Next()
+}
+
+// Колонки результата
+func (self *Rows) Columns() ([]string, error) {
+	if self.columns == nil {
+		columns, err := self.rows.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("sqlx: %s", err)
+		}
+		self.columns = columns
+	}
+	return self.columns, nil
+}
+
+// Сканирование текущей строки в переменные
+func (self *Rows) Scan(a ...interface{}) error {
+	if err := self.rows.Scan(a...); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+	return nil
+}
+
+// Сканирование текущей строки в структуру
+// Карта полей вычисляется один раз для каждого типа
+func (self *Rows) ScanStruct(a interface{}) error {
+	structValuePrt := reflect.ValueOf(a)
+
+	if structValuePrt.Kind() != reflect.Ptr {
+		return errors.New("sqlx: destination not a pointe")
+	}
+	if structValuePrt.IsNil() {
+		return errors.New("sqlx: destination pointer is nil")
+	}
+
+	structValue := structValuePrt.Elem()
+	structType := structValue.Type()
+
+	if structType.Kind() != reflect.Struct {
+		return errors.New("sqlx: invalid variable type, must be a struct")
+	}
+
+	indexes, ok := self.indexes[structType]
+	if !ok {
+		columns, err := self.Columns()
+		if err != nil {
+			return err
+		}
+		indexes, err = structFieldMap(structType).indexes(columns, self.strict)
+		if err != nil {
+			return err
+		}
+		self.indexes[structType] = indexes
+	}
+
+	return self.Scan(pointers(structValue, indexes)...)
+}
+
+// Сканирование текущей строки в карту
+func (self *Rows) ScanMap() (map[string]interface{}, error) {
+	columns, err := self.Columns()
+	if err != nil {
+		return nil, err
+	}
+	return NewScanner(self.rows).rowMap(columns)
+}
+
+// Ошибка, возникшая при переборе строк
+func (self *Rows) Err() error {
+	if err := self.rows.Err(); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+	return nil
+}
+
+// Закрыть результат, повторный вызов безопасен
+func (self *Rows) Close() error {
+	return self.rows.Close()
+}
+
+// Итератор по строкам результата
+// T - структура, map[string]interface{} или значение первой колонки
+// Результат закрывается по окончании перебора или при выходе из цикла
+//
+//	for user, err := range sqlx.Iterate[User](db.Query(sqlx.Table("users"))) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    ...
+//	}
+func Iterate[T any](query *Query) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		rows, err := query.Rows()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		scan := rowScanner[T](rows)
+
+		for rows.Next() {
+			var value T
+			if err := scan(&value); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(value, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// Способ сканирования строки в значение типа T
+func rowScanner[T any](rows *Rows) func(*T) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	switch {
+	case isScalar(typ):
+		return func(value *T) error {
+			columns, err := rows.Columns()
+			if err != nil {
+				return err
+			}
+			values := discard(len(columns))
+			values[0] = value
+			return rows.Scan(values...)
+		}
+	case typ.Kind() == reflect.Struct:
+		return func(value *T) error {
+			return rows.ScanStruct(value)
+		}
+	case typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct:
+		// Для *Struct каждая строка сканируется в новую структуру
+		return func(value *T) error {
+			ptr := reflect.New(typ.Elem())
+			if err := rows.ScanStruct(ptr.Interface()); err != nil {
+				return err
+			}
+			reflect.ValueOf(value).Elem().Set(ptr)
+			return nil
+		}
+	case typ == rowMapType:
+		return func(value *T) error {
+			row, err := rows.ScanMap()
+			if err != nil {
+				return err
+			}
+			*value = any(row).(T)
+			return nil
+		}
+	}
+
+	return func(value *T) error {
+		return fmt.Errorf("sqlx: unsupported iterate type %s", typ)
+	}
+}
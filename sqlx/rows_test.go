@@ -0,0 +1,93 @@
+package sqlx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	db, close := newScannerDB()
+	defer close()
+
+	type User struct {
+		Id   int
+		Name string
+	}
+
+	rows, err := db.Query(Table("users")).Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		user := User{}
+		if err := rows.ScanStruct(&user); err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil || len(users) != 2 || users[0].Name != "Jack" || users[1].Id != 2 {
+		t.Errorf("Unexpected rows result in func TestRows: %+v, %v", users, err)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	db, close := newScannerDB()
+	defer close()
+
+	type User struct {
+		Id   int
+		Name string
+	}
+
+	names := []string{}
+	for user, err := range Iterate[User](db.Query(Table("users"))) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, user.Name)
+	}
+	if len(names) != 2 || names[1] != "Mike" {
+		t.Errorf("Unexpected iterate result in func TestIterate: %v", names)
+	}
+
+	users := []*User{}
+	for user, err := range Iterate[*User](db.Query(Table("users"))) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, user)
+	}
+	if len(users) != 2 || users[0] == users[1] || users[0].Name != "Jack" || users[1].Id != 2 {
+		t.Errorf("Unexpected iterate result in func TestIterate: %+v", users)
+	}
+
+	count := 0
+	for id, err := range Iterate[int64](db.Query(Table("users"))) {
+		if err != nil || id != 1 {
+			t.Errorf("Unexpected iterate result in func TestIterate: %v, %v", id, err)
+		}
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("Expect break to stop iteration in func TestIterate: %d", count)
+	}
+
+	for row, err := range Iterate[map[string]interface{}](db.Query(Table("users"))) {
+		if err != nil || row["name"] != "Jack" {
+			t.Errorf("Unexpected iterate result in func TestIterate: %v, %v", row, err)
+		}
+		break
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range Iterate[User](db.Query(Table("users")).WithContext(ctx)) {
+		if err != context.Canceled {
+			t.Errorf("Expect context.Canceled in func TestIterate, got: %v", err)
+		}
+	}
+}
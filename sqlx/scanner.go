@@ -5,16 +5,47 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 )
 
 var ErrNoRows = sql.ErrNoRows
 
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	rowMapType  = reflect.TypeOf(map[string]interface{}{})
+)
+
+// Тип заполняется одной колонкой целиком: числа, строки, []byte, time.Time, sql.Null*, указатели
+func isScalar(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(scannerType) || t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return false
+	case reflect.Ptr:
+		return isScalar(t.Elem())
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return true
+}
+
 type Scanner struct {
-	rows *sql.Rows
+	rows   *sql.Rows
+	strict bool
 }
 
 func NewScanner(r *sql.Rows) *Scanner {
-	return &Scanner{r}
+	return &Scanner{rows: r}
+}
+
+// Строгий режим: колонка без соответствующего поля структуры является ошибкой
+func (self *Scanner) Strict(enable bool) *Scanner {
+	self.strict = enable
+	return self
 }
 
 // Сканирование результатов запроса в переменные
@@ -25,13 +56,31 @@ func (self *Scanner) Scan(a ...interface{}) error {
 		return errors.New("sqlx: no destination")
 	}
 
-	switch reflect.Indirect(reflect.ValueOf(a[0])).Type().Kind() {
-	case reflect.Struct:
+	value := reflect.ValueOf(a[0])
+	if value.Kind() != reflect.Ptr {
+		return errors.New("sqlx: destination not a pointe")
+	}
+	if value.IsNil() {
+		return errors.New("sqlx: destination pointer is nil")
+	}
+
+	typ := value.Elem().Type()
+
+	switch {
+	case isScalar(typ):
+		return self.scanVars(a...)
+	case typ.Kind() == reflect.Struct:
 		return self.scanStruct(a[0])
-	case reflect.Slice:
-		return self.scanSlice(a[0])
+	case typ == rowMapType:
+		return self.scanMap(a[0])
+	case typ.Kind() == reflect.Map:
+		return self.scanPairs(a[0])
+	case typ.Elem() == rowMapType:
+		return self.scanMaps(a[0])
+	case isScalar(typ.Elem()):
+		return self.scanColumn(a[0], "")
 	default:
-		return self.scanVars(a...)
+		return self.scanSlice(a[0])
 	}
 }
 
@@ -52,43 +101,39 @@ func (self *Scanner) scanSlice(a interface{}) error {
 		return errors.New("sqlx: invalid variable type, must be a slice")
 	}
 
-	columns, err := self.rows.Columns()
-	if err != nil {
-		return fmt.Errorf("sqlx: %s", err)
+	// Поддерживаются []Struct и []*Struct
+	sliceElem := sliceValue.Type().Elem()
+	structType := sliceElem
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
 	}
 
-	for k, v := range columns {
-		columns[k] = toCamel(v)
+	if structType.Kind() != reflect.Struct {
+		return errors.New("sqlx: invalid variable type, must be a slice struct")
 	}
 
-	sliceElem := sliceValue.Type().Elem()
-	structValuePrt := reflect.New(sliceElem)
-	structValue := reflect.Indirect(structValuePrt)
+	columns, err := self.rows.Columns()
+	if err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
 
-	if structValue.Kind() != reflect.Struct {
-		return errors.New("sqlx: invalid variable type, must be a slice struct")
+	indexes, err := structFieldMap(structType).indexes(columns, self.strict)
+	if err != nil {
+		return err
 	}
 
 	for self.rows.Next() {
-		structValuePrt := reflect.New(sliceElem)
-		structValue := reflect.Indirect(structValuePrt)
-
-		fields := deepStructFields(structValuePrt.Interface())
-		values := make([]interface{}, len(columns))
-
-		for i, column := range columns {
-			if field, ok := fields[column]; ok {
-				values[i] = field.Addr().Interface()
-			} else {
-				values[i] = &sql.NullString{}
-			}
-		}
+		structValuePrt := reflect.New(structType)
 
-		if err := self.rows.Scan(values...); err != nil {
+		if err := self.rows.Scan(pointers(structValuePrt.Elem(), indexes)...); err != nil {
 			return fmt.Errorf("sqlx: %s", err)
 		}
 
-		sliceValue.Set(reflect.Append(sliceValue, structValue))
+		if sliceElem.Kind() == reflect.Ptr {
+			sliceValue.Set(reflect.Append(sliceValue, structValuePrt))
+		} else {
+			sliceValue.Set(reflect.Append(sliceValue, structValuePrt.Elem()))
+		}
 	}
 
 	if err := self.rows.Err(); err != nil {
@@ -136,25 +181,172 @@ func (self *Scanner) scanStruct(a interface{}) error {
 		return fmt.Errorf("sqlx: %s", err)
 	}
 
-	for k, v := range columns {
-		columns[k] = toCamel(v)
+	indexes, err := structFieldMap(structType).indexes(columns, self.strict)
+	if err != nil {
+		return err
+	}
+
+	if err := self.rows.Scan(pointers(structValue, indexes)...); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	if err := self.rows.Close(); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	return nil
+}
+
+// Сканирование в переменные
+func (self *Scanner) scanVars(a ...interface{}) error {
+	if !self.rows.Next() {
+		if err := self.rows.Err(); err != nil {
+			return fmt.Errorf("sqlx: %s", err)
+		}
+		return ErrNoRows
+	}
+
+	if err := self.rows.Scan(a...); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	if err := self.rows.Close(); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	return nil
+}
+
+// Сканирование строки в map[string]interface{}
+func (self *Scanner) scanMap(a interface{}) error {
+	if !self.rows.Next() {
+		if err := self.rows.Err(); err != nil {
+			return fmt.Errorf("sqlx: %s", err)
+		}
+		return ErrNoRows
+	}
+
+	columns, err := self.rows.Columns()
+	if err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	row, err := self.rowMap(columns)
+	if err != nil {
+		return err
+	}
+
+	dest := a.(*map[string]interface{})
+	if *dest == nil {
+		*dest = row
+	} else {
+		for k, v := range row {
+			(*dest)[k] = v
+		}
 	}
 
-	fields := deepStructFields(structValuePrt.Interface())
+	if err := self.rows.Close(); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	return nil
+}
+
+// Сканирование в []map[string]interface{}
+func (self *Scanner) scanMaps(a interface{}) error {
+	sliceValue := reflect.ValueOf(a).Elem()
+
+	columns, err := self.rows.Columns()
+	if err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	for self.rows.Next() {
+		row, err := self.rowMap(columns)
+		if err != nil {
+			return err
+		}
+		sliceValue.Set(reflect.Append(sliceValue, reflect.ValueOf(row)))
+	}
+
+	if err := self.rows.Err(); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	if sliceValue.Len() == 0 {
+		return ErrNoRows
+	}
+
+	if err := self.rows.Close(); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	return nil
+}
+
+// Текущая строка в виде карты, []byte преобразуется в string
+func (self *Scanner) rowMap(columns []string) (map[string]interface{}, error) {
 	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for k := range values {
+		pointers[k] = &values[k]
+	}
 
-	for i, column := range columns {
-		if field, ok := fields[column]; ok {
-			values[i] = field.Addr().Interface()
+	if err := self.rows.Scan(pointers...); err != nil {
+		return nil, fmt.Errorf("sqlx: %s", err)
+	}
+
+	row := make(map[string]interface{}, len(columns))
+	for k, column := range columns {
+		if b, ok := values[k].([]byte); ok {
+			row[column] = string(b)
 		} else {
-			values[i] = &sql.NullString{}
+			row[column] = values[k]
 		}
 	}
 
-	if err := self.rows.Scan(values...); err != nil {
+	return row, nil
+}
+
+// Сканирование пар ключ-значение из двух колонок в map[K]V
+func (self *Scanner) scanPairs(a interface{}) error {
+	mapValue := reflect.ValueOf(a).Elem()
+	mapType := mapValue.Type()
+
+	columns, err := self.rows.Columns()
+	if err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	if len(columns) != 2 {
+		return errors.New("sqlx: map destination requires two columns: key and value")
+	}
+
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapType))
+	}
+
+	found := false
+	for self.rows.Next() {
+		key := reflect.New(mapType.Key())
+		value := reflect.New(mapType.Elem())
+
+		if err := self.rows.Scan(key.Interface(), value.Interface()); err != nil {
+			return fmt.Errorf("sqlx: %s", err)
+		}
+
+		mapValue.SetMapIndex(key.Elem(), value.Elem())
+		found = true
+	}
+
+	if err := self.rows.Err(); err != nil {
 		return fmt.Errorf("sqlx: %s", err)
 	}
 
+	if !found {
+		return ErrNoRows
+	}
+
 	if err := self.rows.Close(); err != nil {
 		return fmt.Errorf("sqlx: %s", err)
 	}
@@ -162,8 +354,65 @@ func (self *Scanner) scanStruct(a interface{}) error {
 	return nil
 }
 
-// Сканирование в переменные
-func (self *Scanner) scanVars(a ...interface{}) error {
+// Сканирование одной колонки в срез значений
+// Пустое имя колонки - первая колонка результата
+func (self *Scanner) scanColumn(a interface{}, column string) error {
+	sliceValuePrt := reflect.ValueOf(a)
+
+	if sliceValuePrt.Kind() != reflect.Ptr {
+		return errors.New("sqlx: destination not a pointe")
+	}
+	if sliceValuePrt.IsNil() {
+		return errors.New("sqlx: destination pointer is nil")
+	}
+
+	sliceValue := sliceValuePrt.Elem()
+
+	if sliceValue.Kind() != reflect.Slice {
+		return errors.New("sqlx: invalid variable type, must be a slice")
+	}
+
+	columns, err := self.rows.Columns()
+	if err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	index, err := columnIndex(columns, column)
+	if err != nil {
+		return err
+	}
+
+	sliceElem := sliceValue.Type().Elem()
+	values := discard(len(columns))
+
+	for self.rows.Next() {
+		value := reflect.New(sliceElem)
+		values[index] = value.Interface()
+
+		if err := self.rows.Scan(values...); err != nil {
+			return fmt.Errorf("sqlx: %s", err)
+		}
+
+		sliceValue.Set(reflect.Append(sliceValue, value.Elem()))
+	}
+
+	if err := self.rows.Err(); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	if sliceValue.Len() == 0 {
+		return ErrNoRows
+	}
+
+	if err := self.rows.Close(); err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	return nil
+}
+
+// Сканирование первой колонки первой строки, остальные колонки игнорируются
+func (self *Scanner) scanValue(a interface{}) error {
 	if !self.rows.Next() {
 		if err := self.rows.Err(); err != nil {
 			return fmt.Errorf("sqlx: %s", err)
@@ -171,7 +420,15 @@ func (self *Scanner) scanVars(a ...interface{}) error {
 		return ErrNoRows
 	}
 
-	if err := self.rows.Scan(a...); err != nil {
+	columns, err := self.rows.Columns()
+	if err != nil {
+		return fmt.Errorf("sqlx: %s", err)
+	}
+
+	values := discard(len(columns))
+	values[0] = a
+
+	if err := self.rows.Scan(values...); err != nil {
 		return fmt.Errorf("sqlx: %s", err)
 	}
 
@@ -181,3 +438,29 @@ func (self *Scanner) scanVars(a ...interface{}) error {
 
 	return nil
 }
+
+// Позиция колонки по имени, имя таблицы в "users.id" не учитывается
+func columnIndex(columns []string, column string) (int, error) {
+	if len(columns) == 0 {
+		return 0, errors.New("sqlx: no columns in result")
+	}
+	if column == "" {
+		return 0, nil
+	}
+	column = column[strings.LastIndex(column, ".")+1:]
+	for k, v := range columns {
+		if v == column {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("sqlx: column %s not found in result", column)
+}
+
+// Приемники для колонок, значения которых не нужны
+func discard(n int) []interface{} {
+	values := make([]interface{}, n)
+	for k := range values {
+		values[k] = new(interface{})
+	}
+	return values
+}
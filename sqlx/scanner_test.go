@@ -0,0 +1,102 @@
+package sqlx
+
+import (
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"testing"
+)
+
+func newScannerDB() (*DB, func() error) {
+	db, _ := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		if query == `SELECT EXISTS( SELECT * FROM "users" )` {
+			return fakeResponse{
+				columns: []string{"exists"},
+				rows:    [][]sqldriver.Value{{true}},
+			}
+		}
+		return fakeResponse{
+			columns: []string{"id", "name", "nickname"},
+			rows: [][]sqldriver.Value{
+				{int64(1), []byte("Jack"), nil},
+				{int64(2), "Mike", "mk"},
+			},
+		}
+	})
+	return DataBase(db), db.Close
+}
+
+func TestScanMaps(t *testing.T) {
+	db, close := newScannerDB()
+	defer close()
+
+	rows := []map[string]interface{}{}
+	if err := db.Query(Table("users")).Scan(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0]["name"] != "Jack" || rows[0]["nickname"] != nil || rows[1]["id"] != int64(2) {
+		t.Errorf("Unexpected scan result in func TestScanMaps: %v", rows)
+	}
+
+	var row map[string]interface{}
+	if err := db.Query(Table("users")).Scan(&row); err != nil || row["name"] != "Jack" {
+		t.Errorf("Unexpected scan result in func TestScanMaps: %v, %v", row, err)
+	}
+
+	pairs := map[int64]string{}
+	if err := db.QueryRaw("SELECT id, name FROM users").Scan(&pairs); err == nil {
+		t.Errorf("Expect error for three columns into map in func TestScanMaps")
+	}
+}
+
+func TestScanColumns(t *testing.T) {
+	db, close := newScannerDB()
+	defer close()
+
+	ids := []int64{}
+	if err := db.Query(Table("users")).Scan(&ids); err != nil || len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("Unexpected scan result in func TestScanColumns: %v, %v", ids, err)
+	}
+
+	nicknames := []*string{}
+	if err := db.Query(Table("users")).Pluck("users.nickname", &nicknames); err != nil || len(nicknames) != 2 || nicknames[0] != nil || *nicknames[1] != "mk" {
+		t.Errorf("Unexpected pluck result in func TestScanColumns: %v, %v", nicknames, err)
+	}
+
+	names := []sql.NullString{}
+	if err := db.Query(Table("users")).Pluck("nickname", &names); err != nil || names[0].Valid || names[1].String != "mk" {
+		t.Errorf("Unexpected pluck result in func TestScanColumns: %v, %v", names, err)
+	}
+
+	if err := db.Query(Table("users")).Pluck("email", &names); err == nil {
+		t.Errorf("Expect error for unknown column in func TestScanColumns")
+	}
+
+	var id int
+	if err := db.Query(Table("users")).Value(&id); err != nil || id != 1 {
+		t.Errorf("Unexpected value result in func TestScanColumns: %v, %v", id, err)
+	}
+
+	exists, err := db.Query(Table("users")).Exists()
+	if err != nil || !exists {
+		t.Errorf("Unexpected exists result in func TestScanColumns: %v, %v", exists, err)
+	}
+}
+
+func TestScanPointers(t *testing.T) {
+	db, close := newScannerDB()
+	defer close()
+
+	type User struct {
+		Id       int
+		Name     string
+		Nickname *string
+	}
+
+	users := []*User{}
+	if err := db.Query(Table("users")).Scan(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Nickname != nil || users[1].Nickname == nil || *users[1].Nickname != "mk" {
+		t.Errorf("Unexpected scan result in func TestScanPointers: %+v", users)
+	}
+}
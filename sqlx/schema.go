@@ -0,0 +1,398 @@
+package sqlx
+
+import (
+	"context"
+	"strings"
+)
+
+// Построитель схемы базы данных
+//
+//	blueprint := sqlx.Schema.Create("users", func(t *sqlx.Blueprint) {
+//	    t.ID()
+//	    t.String("name", 255).Unique()
+//	    t.BigInteger("group_id").Unsigned().Nullable()
+//	    t.Foreign("group_id").References("id").On("groups").OnDelete("cascade")
+//	    t.Timestamps()
+//	})
+//	err := dbx.ExecSchema(ctx, blueprint)
+var Schema SchemaBuilder
+
+type SchemaBuilder struct{}
+
+// Создание таблицы
+func (SchemaBuilder) Create(table string, fn func(*Blueprint)) *Blueprint {
+	return newBlueprint(table, "create", fn)
+}
+
+// Создание таблицы, если она не существует
+// Индексы создаются отдельными запросами, поэтому их стоит задавать в Create
+func (SchemaBuilder) CreateIfNotExists(table string, fn func(*Blueprint)) *Blueprint {
+	b := newBlueprint(table, "create", fn)
+	b.ifExists = true
+	return b
+}
+
+// Изменение таблицы
+func (SchemaBuilder) Alter(table string, fn func(*Blueprint)) *Blueprint {
+	return newBlueprint(table, "alter", fn)
+}
+
+// Удаление таблицы
+func (SchemaBuilder) Drop(table string) *Blueprint {
+	return newBlueprint(table, "drop", nil)
+}
+
+// Удаление таблицы, если она существует
+func (SchemaBuilder) DropIfExists(table string) *Blueprint {
+	b := newBlueprint(table, "drop", nil)
+	b.ifExists = true
+	return b
+}
+
+// Переименование таблицы
+func (SchemaBuilder) Rename(from, to string) *Blueprint {
+	b := newBlueprint(from, "rename", nil)
+	b.rename = to
+	return b
+}
+
+// Описание таблицы
+type Blueprint struct {
+	table    string
+	action   string
+	ifExists bool
+	rename   string
+	columns  []*Column
+	commands []*schemaCommand
+}
+
+func newBlueprint(table, action string, fn func(*Blueprint)) *Blueprint {
+	b := &Blueprint{table: table, action: action}
+	if fn != nil {
+		fn(b)
+	}
+	return b
+}
+
+// Команда изменения таблицы: индексы, внешние ключи, удаление и переименование колонок
+type schemaCommand struct {
+	kind       string
+	name       string
+	columns    []string
+	to         string
+	references []string
+	on         string
+	onDelete   string
+	onUpdate   string
+}
+
+// Колонка таблицы
+type Column struct {
+	blueprint *Blueprint
+	name      string
+	kind      string
+	length    int
+	precision int
+	scale     int
+	nullable  bool
+	unsigned  bool
+	primary   bool
+	value     interface{}
+	defaults  bool
+}
+
+func (self *Blueprint) addColumn(kind, name string) *Column {
+	c := &Column{blueprint: self, name: name, kind: kind}
+	self.columns = append(self.columns, c)
+	return c
+}
+
+func (self *Blueprint) addCommand(kind string, columns []string) *schemaCommand {
+	c := &schemaCommand{kind: kind, columns: columns}
+	self.commands = append(self.commands, c)
+	return c
+}
+
+// Автоинкрементный первичный ключ id типа BIGINT
+func (self *Blueprint) ID() *Column {
+	return self.BigIncrements("id")
+}
+
+// Автоинкрементный первичный ключ типа INTEGER
+func (self *Blueprint) Increments(name string) *Column {
+	return self.addColumn("increments", name)
+}
+
+// Автоинкрементный первичный ключ типа BIGINT
+func (self *Blueprint) BigIncrements(name string) *Column {
+	return self.addColumn("bigIncrements", name)
+}
+
+func (self *Blueprint) Integer(name string) *Column {
+	return self.addColumn("integer", name)
+}
+
+func (self *Blueprint) SmallInteger(name string) *Column {
+	return self.addColumn("smallInteger", name)
+}
+
+func (self *Blueprint) BigInteger(name string) *Column {
+	return self.addColumn("bigInteger", name)
+}
+
+func (self *Blueprint) Float(name string) *Column {
+	return self.addColumn("float", name)
+}
+
+func (self *Blueprint) Double(name string) *Column {
+	return self.addColumn("double", name)
+}
+
+// Число с фиксированной точностью
+func (self *Blueprint) Decimal(name string, precision, scale int) *Column {
+	c := self.addColumn("decimal", name)
+	c.precision, c.scale = precision, scale
+	return c
+}
+
+func (self *Blueprint) Boolean(name string) *Column {
+	return self.addColumn("boolean", name)
+}
+
+// Строка ограниченной длины
+func (self *Blueprint) String(name string, length int) *Column {
+	c := self.addColumn("string", name)
+	c.length = length
+	return c
+}
+
+func (self *Blueprint) Text(name string) *Column {
+	return self.addColumn("text", name)
+}
+
+func (self *Blueprint) Binary(name string) *Column {
+	return self.addColumn("binary", name)
+}
+
+func (self *Blueprint) JSON(name string) *Column {
+	return self.addColumn("json", name)
+}
+
+func (self *Blueprint) UUID(name string) *Column {
+	return self.addColumn("uuid", name)
+}
+
+func (self *Blueprint) Date(name string) *Column {
+	return self.addColumn("date", name)
+}
+
+func (self *Blueprint) Time(name string) *Column {
+	return self.addColumn("time", name)
+}
+
+func (self *Blueprint) DateTime(name string) *Column {
+	return self.addColumn("dateTime", name)
+}
+
+func (self *Blueprint) Timestamp(name string) *Column {
+	return self.addColumn("timestamp", name)
+}
+
+// Колонки created_at и updated_at, допускающие NULL
+func (self *Blueprint) Timestamps() {
+	self.Timestamp("created_at").Nullable()
+	self.Timestamp("updated_at").Nullable()
+}
+
+// Составной первичный ключ
+func (self *Blueprint) Primary(columns ...string) *Constraint {
+	return &Constraint{self.addCommand("primary", columns)}
+}
+
+// Индекс, имя по умолчанию: table_column_index
+func (self *Blueprint) Index(columns ...string) *Constraint {
+	return &Constraint{self.addCommand("index", columns)}
+}
+
+// Уникальный индекс, имя по умолчанию: table_column_unique
+func (self *Blueprint) Unique(columns ...string) *Constraint {
+	return &Constraint{self.addCommand("unique", columns)}
+}
+
+// Внешний ключ, имя по умолчанию: table_column_foreign
+// SQLite не поддерживает добавление внешних ключей в существующую таблицу
+func (self *Blueprint) Foreign(columns ...string) *Constraint {
+	return &Constraint{self.addCommand("foreign", columns)}
+}
+
+// Удаление колонок, SQLite 3.35+
+func (self *Blueprint) DropColumn(columns ...string) {
+	self.addCommand("dropColumn", columns)
+}
+
+func (self *Blueprint) RenameColumn(from, to string) {
+	self.addCommand("renameColumn", []string{from}).to = to
+}
+
+// Удаление индекса по имени
+func (self *Blueprint) DropIndex(name string) {
+	self.addCommand("dropIndex", nil).name = name
+}
+
+// Удаление уникального индекса по имени
+func (self *Blueprint) DropUnique(name string) {
+	self.addCommand("dropIndex", nil).name = name
+}
+
+// Удаление внешнего ключа по имени
+func (self *Blueprint) DropForeign(name string) {
+	self.addCommand("dropForeign", nil).name = name
+}
+
+// Запросы в диалекте драйвера по умолчанию
+// Паникует, если диалект не поддерживает операцию, ExecSchema возвращает ошибку
+func (self *Blueprint) Sql() []string {
+	return self.mustCompile("")
+}
+
+// Запросы в указанном диалекте
+func (self *Blueprint) ToSQL(dialect string) []string {
+	if dialect == "" {
+		panic("sqlx: dialect is not defined")
+	}
+	return self.mustCompile(dialect)
+}
+
+func (self *Blueprint) mustCompile(dialect string) []string {
+	queries, err := self.compile(dialect)
+	if err != nil {
+		panic(err.Error())
+	}
+	return queries
+}
+
+func (self *Blueprint) compile(dialect string) ([]string, error) {
+	glammar := driver
+	if dialect != "" {
+		glammar = lookupDriver(dialect)
+	}
+	if glammar == nil {
+		panic("sqlx: driver is not defined")
+	}
+	return glammar().compileSchema(self)
+}
+
+// Допускает NULL
+func (self *Column) Nullable() *Column {
+	self.nullable = true
+	return self
+}
+
+// Значение по умолчанию, выражения передаются через sqlx.Raw
+//
+//	t.Timestamp("created_at").Default(sqlx.Raw("CURRENT_TIMESTAMP"))
+func (self *Column) Default(v interface{}) *Column {
+	self.value, self.defaults = v, true
+	return self
+}
+
+// Беззнаковое число, учитывается только в MySQL
+func (self *Column) Unsigned() *Column {
+	self.unsigned = true
+	return self
+}
+
+// Первичный ключ из этой колонки
+func (self *Column) Primary() *Column {
+	self.primary = true
+	return self
+}
+
+// Индекс по этой колонке
+func (self *Column) Index() *Column {
+	self.blueprint.Index(self.name)
+	return self
+}
+
+// Уникальный индекс по этой колонке
+func (self *Column) Unique() *Column {
+	self.blueprint.Unique(self.name)
+	return self
+}
+
+// Индекс, первичный или внешний ключ
+type Constraint struct {
+	command *schemaCommand
+}
+
+// Имя индекса или ограничения
+func (self *Constraint) Name(name string) *Constraint {
+	self.command.name = name
+	return self
+}
+
+// Колонки, на которые ссылается внешний ключ
+func (self *Constraint) References(columns ...string) *Constraint {
+	self.command.references = columns
+	return self
+}
+
+// Таблица, на которую ссылается внешний ключ
+func (self *Constraint) On(table string) *Constraint {
+	self.command.on = table
+	return self
+}
+
+// Действие при удалении: cascade, restrict, set null, no action
+func (self *Constraint) OnDelete(action string) *Constraint {
+	self.command.onDelete = action
+	return self
+}
+
+// Действие при изменении: cascade, restrict, set null, no action
+func (self *Constraint) OnUpdate(action string) *Constraint {
+	self.command.onUpdate = action
+	return self
+}
+
+// Имя индекса или ограничения по умолчанию
+func (self *schemaCommand) index(table string) string {
+	if self.name != "" {
+		return self.name
+	}
+	name := table + "_" + strings.Join(self.columns, "_") + "_" + self.kind
+	return strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToLower(name))
+}
+
+// Выполнение запросов схемы
+// В MySQL DDL фиксирует транзакцию неявно, в PostgreSQL и SQLite выполняется в ней
+func (self *DB) ExecSchema(ctx context.Context, blueprints ...*Blueprint) error {
+	return execSchema(ctx, self.dialect, self.QueryRaw, blueprints)
+}
+
+func (self *Tx) ExecSchema(ctx context.Context, blueprints ...*Blueprint) error {
+	return execSchema(ctx, self.dialect, self.QueryRaw, blueprints)
+}
+
+func (self *Conn) ExecSchema(ctx context.Context, blueprints ...*Blueprint) error {
+	return execSchema(ctx, self.dialect, self.QueryRaw, blueprints)
+}
+
+// Все описания компилируются до выполнения, чтобы неподдерживаемая
+// операция не оставила схему измененной наполовину
+func execSchema(ctx context.Context, dialect string, raw func(string, ...interface{}) *Query, blueprints []*Blueprint) error {
+	queries := make([]string, 0, len(blueprints))
+	for _, b := range blueprints {
+		compiled, err := b.compile(dialect)
+		if err != nil {
+			return err
+		}
+		queries = append(queries, compiled...)
+	}
+	for _, query := range queries {
+		if _, err := raw(query).ExecContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
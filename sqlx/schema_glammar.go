@@ -0,0 +1,268 @@
+package sqlx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Типы колонок MySQL
+var mysqlColumnTypes = map[string]string{
+	"increments":    "INT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY",
+	"bigIncrements": "BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY",
+	"integer":       "INT",
+	"smallInteger":  "SMALLINT",
+	"bigInteger":    "BIGINT",
+	"float":         "FLOAT",
+	"double":        "DOUBLE",
+	"decimal":       "DECIMAL({precision}, {scale})",
+	"boolean":       "TINYINT(1)",
+	"string":        "VARCHAR({length})",
+	"text":          "TEXT",
+	"binary":        "BLOB",
+	"json":          "JSON",
+	"uuid":          "CHAR(36)",
+	"date":          "DATE",
+	"time":          "TIME",
+	"dateTime":      "DATETIME",
+	"timestamp":     "TIMESTAMP",
+}
+
+// Компиляция описания таблицы в запросы DDL
+// Операции, которые диалект не поддерживает, возвращаются ошибкой
+func (self *baseGlammar) compileSchema(b *Blueprint) ([]string, error) {
+	switch b.action {
+	case "create":
+		return self.compileCreateTable(b)
+	case "alter":
+		return self.compileAlterTable(b)
+	case "drop":
+		if b.ifExists {
+			return []string{"DROP TABLE IF EXISTS " + self.wrap(b.table)}, nil
+		}
+		return []string{"DROP TABLE " + self.wrap(b.table)}, nil
+	case "rename":
+		return []string{"ALTER TABLE " + self.wrap(b.table) + " RENAME TO " + self.wrap(b.rename)}, nil
+	}
+	return nil, fmt.Errorf("sqlx: unknown schema action '%s'", b.action)
+}
+
+func (self *baseGlammar) compileCreateTable(b *Blueprint) ([]string, error) {
+	definitions := make([]string, 0, len(b.columns))
+	for _, c := range b.columns {
+		definitions = append(definitions, self.columnDefinition(c))
+	}
+
+	if primary := primaryColumns(b); len(primary) > 0 {
+		definitions = append(definitions, "PRIMARY KEY ( "+self.wrapList(primary)+" )")
+	}
+
+	indexes := make([]string, 0)
+	for _, c := range b.commands {
+		switch c.kind {
+		case "primary":
+		case "foreign":
+			foreign, err := self.foreignKey(b, c)
+			if err != nil {
+				return nil, err
+			}
+			definitions = append(definitions, foreign)
+		case "index", "unique":
+			indexes = append(indexes, self.createIndex(b, c))
+		default:
+			return nil, fmt.Errorf("sqlx: command '%s' is not allowed when creating a table", c.kind)
+		}
+	}
+
+	create := "CREATE TABLE "
+	if b.ifExists {
+		create = "CREATE TABLE IF NOT EXISTS "
+	}
+
+	return append([]string{create + self.wrap(b.table) + " ( " + strings.Join(definitions, ", ") + " )"}, indexes...), nil
+}
+
+func (self *baseGlammar) compileAlterTable(b *Blueprint) ([]string, error) {
+	table := "ALTER TABLE " + self.wrap(b.table) + " "
+	queries := make([]string, 0, len(b.columns)+len(b.commands))
+
+	for _, c := range b.columns {
+		queries = append(queries, table+"ADD COLUMN "+self.columnDefinition(c))
+	}
+
+	if primary := primaryColumns(b); len(primary) > 0 {
+		query, err := self.glammar.compileAddPrimary(b, primary)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+
+	for _, c := range b.commands {
+		switch c.kind {
+		case "primary":
+		case "foreign":
+			query, err := self.glammar.compileAddForeign(b, c)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, query)
+		case "index", "unique":
+			queries = append(queries, self.createIndex(b, c))
+		case "dropColumn":
+			for _, column := range c.columns {
+				queries = append(queries, table+"DROP COLUMN "+self.wrap(column))
+			}
+		case "renameColumn":
+			queries = append(queries, table+"RENAME COLUMN "+self.wrap(c.columns[0])+" TO "+self.wrap(c.to))
+		case "dropIndex":
+			queries = append(queries, self.glammar.compileDropIndex(b, c))
+		case "dropForeign":
+			query, err := self.glammar.compileDropForeign(b, c)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, query)
+		}
+	}
+
+	return queries, nil
+}
+
+// Колонки первичного ключа, кроме автоинкрементных
+func primaryColumns(b *Blueprint) []string {
+	columns := make([]string, 0)
+	for _, c := range b.columns {
+		if c.primary && c.kind != "increments" && c.kind != "bigIncrements" {
+			columns = append(columns, c.name)
+		}
+	}
+	for _, c := range b.commands {
+		if c.kind == "primary" {
+			columns = append(columns, c.columns...)
+		}
+	}
+	return columns
+}
+
+func (self *baseGlammar) wrapList(columns []string) string {
+	values := make([]interface{}, len(columns))
+	for k, v := range columns {
+		values[k] = v
+	}
+	return self.wrap(values...)
+}
+
+func (self *baseGlammar) columnDefinition(c *Column) string {
+	return self.wrap(c.name) + " " + self.glammar.columnType(c) + self.glammar.columnModifiers(c)
+}
+
+// Тип колонки с подставленными длиной и точностью
+func (self *baseGlammar) typeColumn(types map[string]string, c *Column) string {
+	return strings.NewReplacer(
+		"{length}", strconv.Itoa(c.length),
+		"{precision}", strconv.Itoa(c.precision),
+		"{scale}", strconv.Itoa(c.scale),
+	).Replace(types[c.kind])
+}
+
+func (self *baseGlammar) columnType(c *Column) string {
+	return self.typeColumn(mysqlColumnTypes, c)
+}
+
+func (self *baseGlammar) columnModifiers(c *Column) string {
+	if c.kind == "increments" || c.kind == "bigIncrements" {
+		return ""
+	}
+
+	var buf strings.Builder
+	switch c.kind {
+	case "integer", "smallInteger", "bigInteger", "float", "double", "decimal":
+		if c.unsigned {
+			buf.WriteString(" UNSIGNED")
+		}
+	}
+	if c.nullable {
+		buf.WriteString(" NULL")
+	} else {
+		buf.WriteString(" NOT NULL")
+	}
+	if c.defaults {
+		buf.WriteString(" DEFAULT " + defaultValue(self.glammar, c.value))
+	}
+	return buf.String()
+}
+
+// Модификаторы колонки без UNSIGNED и явного NULL
+func columnModifiers(g glammar, c *Column) string {
+	if c.kind == "increments" || c.kind == "bigIncrements" {
+		return ""
+	}
+
+	modifiers := ""
+	if !c.nullable {
+		modifiers = " NOT NULL"
+	}
+	if c.defaults {
+		modifiers += " DEFAULT " + defaultValue(g, c.value)
+	}
+	return modifiers
+}
+
+// Значение по умолчанию, выражения sqlx.Raw подставляются как есть
+func defaultValue(g glammar, v interface{}) string {
+	if exp, ok := v.(Expression); ok {
+		return exp.String()
+	}
+	return g.literalValue(v)
+}
+
+// Значение в виде литерала диалекта
+func (self *baseGlammar) literalValue(v interface{}) string {
+	return literal("mysql", v)
+}
+
+func (self *baseGlammar) createIndex(b *Blueprint, c *schemaCommand) string {
+	create := "CREATE INDEX "
+	if c.kind == "unique" {
+		create = "CREATE UNIQUE INDEX "
+	}
+	return create + self.wrap(c.index(b.table)) + " ON " + self.wrap(b.table) + " ( " + self.wrapList(c.columns) + " )"
+}
+
+func (self *baseGlammar) foreignKey(b *Blueprint, c *schemaCommand) (string, error) {
+	if c.on == "" || len(c.references) == 0 {
+		return "", errors.New("sqlx: foreign key requires referenced table and columns")
+	}
+
+	query := "CONSTRAINT " + self.wrap(c.index(b.table)) + " FOREIGN KEY ( " + self.wrapList(c.columns) + " )" +
+		" REFERENCES " + self.wrap(c.on) + " ( " + self.wrapList(c.references) + " )"
+	if c.onDelete != "" {
+		query += " ON DELETE " + strings.ToUpper(c.onDelete)
+	}
+	if c.onUpdate != "" {
+		query += " ON UPDATE " + strings.ToUpper(c.onUpdate)
+	}
+	return query, nil
+}
+
+func (self *baseGlammar) compileAddPrimary(b *Blueprint, columns []string) (string, error) {
+	return "ALTER TABLE " + self.wrap(b.table) + " ADD PRIMARY KEY ( " + self.wrapList(columns) + " )", nil
+}
+
+func (self *baseGlammar) compileAddForeign(b *Blueprint, c *schemaCommand) (string, error) {
+	foreign, err := self.foreignKey(b, c)
+	if err != nil {
+		return "", err
+	}
+	return "ALTER TABLE " + self.wrap(b.table) + " ADD " + foreign, nil
+}
+
+func (self *baseGlammar) compileDropIndex(b *Blueprint, c *schemaCommand) string {
+	return "DROP INDEX " + self.wrap(c.name) + " ON " + self.wrap(b.table)
+}
+
+func (self *baseGlammar) compileDropForeign(b *Blueprint, c *schemaCommand) (string, error) {
+	return "ALTER TABLE " + self.wrap(b.table) + " DROP FOREIGN KEY " + self.wrap(c.name), nil
+}
@@ -0,0 +1,130 @@
+package sqlx
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func schemaEqual(t *testing.T, name string, result, expect []string) {
+	if strings.Join(result, ";\n") != strings.Join(expect, ";\n") {
+		t.Errorf("Expect result to equal in func %s.\nResult: %s\nExpect: %s", name, strings.Join(result, ";\n"), strings.Join(expect, ";\n"))
+	}
+}
+
+func schemaUsers() *Blueprint {
+	return Schema.Create("users", func(t *Blueprint) {
+		t.ID()
+		t.String("name", 255).Unique()
+		t.Decimal("balance", 10, 2).Unsigned().Default(0)
+		t.Boolean("active").Default(true)
+		t.BigInteger("group_id").Unsigned().Nullable().Index()
+		t.Foreign("group_id").References("id").On("groups").OnDelete("cascade")
+		t.Timestamps()
+	})
+}
+
+func TestSchemaCreate(t *testing.T) {
+	schemaEqual(t, "TestSchemaCreate", schemaUsers().ToSQL("postgres"), []string{
+		`CREATE TABLE "users" ( "id" BIGSERIAL PRIMARY KEY, "name" VARCHAR(255) NOT NULL, "balance" NUMERIC(10, 2) NOT NULL DEFAULT 0, "active" BOOLEAN NOT NULL DEFAULT TRUE, "group_id" BIGINT, "created_at" TIMESTAMP, "updated_at" TIMESTAMP, CONSTRAINT "users_group_id_foreign" FOREIGN KEY ( "group_id" ) REFERENCES "groups" ( "id" ) ON DELETE CASCADE )`,
+		`CREATE UNIQUE INDEX "users_name_unique" ON "users" ( "name" )`,
+		`CREATE INDEX "users_group_id_index" ON "users" ( "group_id" )`,
+	})
+
+	schemaEqual(t, "TestSchemaCreate", schemaUsers().ToSQL("mysql"), []string{
+		"CREATE TABLE `users` ( `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY, `name` VARCHAR(255) NOT NULL, `balance` DECIMAL(10, 2) UNSIGNED NOT NULL DEFAULT 0, `active` TINYINT(1) NOT NULL DEFAULT 1, `group_id` BIGINT UNSIGNED NULL, `created_at` TIMESTAMP NULL, `updated_at` TIMESTAMP NULL, CONSTRAINT `users_group_id_foreign` FOREIGN KEY ( `group_id` ) REFERENCES `groups` ( `id` ) ON DELETE CASCADE )",
+		"CREATE UNIQUE INDEX `users_name_unique` ON `users` ( `name` )",
+		"CREATE INDEX `users_group_id_index` ON `users` ( `group_id` )",
+	})
+
+	schemaEqual(t, "TestSchemaCreate", schemaUsers().ToSQL("sqlite3"), []string{
+		"CREATE TABLE `users` ( `id` INTEGER PRIMARY KEY AUTOINCREMENT, `name` VARCHAR(255) NOT NULL, `balance` NUMERIC NOT NULL DEFAULT 0, `active` INTEGER NOT NULL DEFAULT 1, `group_id` INTEGER, `created_at` DATETIME, `updated_at` DATETIME, CONSTRAINT `users_group_id_foreign` FOREIGN KEY ( `group_id` ) REFERENCES `groups` ( `id` ) ON DELETE CASCADE )",
+		"CREATE UNIQUE INDEX `users_name_unique` ON `users` ( `name` )",
+		"CREATE INDEX `users_group_id_index` ON `users` ( `group_id` )",
+	})
+
+	result := Schema.CreateIfNotExists("user_roles", func(t *Blueprint) {
+		t.Integer("user_id")
+		t.String("role", 32).Default("guest's")
+		t.Timestamp("created_at").Default(Raw("CURRENT_TIMESTAMP"))
+		t.Primary("user_id", "role")
+	}).ToSQL("postgres")
+	schemaEqual(t, "TestSchemaCreate", result, []string{
+		`CREATE TABLE IF NOT EXISTS "user_roles" ( "user_id" INTEGER NOT NULL, "role" VARCHAR(32) NOT NULL DEFAULT 'guest''s', "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY ( "user_id", "role" ) )`,
+	})
+}
+
+func TestSchemaAlter(t *testing.T) {
+	blueprint := func() *Blueprint {
+		return Schema.Alter("users", func(t *Blueprint) {
+			t.String("email", 100).Nullable()
+			t.Unique("email").Name("users_email")
+			t.RenameColumn("name", "login")
+			t.DropColumn("active", "balance")
+			t.DropIndex("users_group_id_index")
+		})
+	}
+
+	schemaEqual(t, "TestSchemaAlter", blueprint().ToSQL("postgres"), []string{
+		`ALTER TABLE "users" ADD COLUMN "email" VARCHAR(100)`,
+		`CREATE UNIQUE INDEX "users_email" ON "users" ( "email" )`,
+		`ALTER TABLE "users" RENAME COLUMN "name" TO "login"`,
+		`ALTER TABLE "users" DROP COLUMN "active"`,
+		`ALTER TABLE "users" DROP COLUMN "balance"`,
+		`DROP INDEX "users_group_id_index"`,
+	})
+
+	schemaEqual(t, "TestSchemaAlter", blueprint().ToSQL("mysql"), []string{
+		"ALTER TABLE `users` ADD COLUMN `email` VARCHAR(100) NULL",
+		"CREATE UNIQUE INDEX `users_email` ON `users` ( `email` )",
+		"ALTER TABLE `users` RENAME COLUMN `name` TO `login`",
+		"ALTER TABLE `users` DROP COLUMN `active`",
+		"ALTER TABLE `users` DROP COLUMN `balance`",
+		"DROP INDEX `users_group_id_index` ON `users`",
+	})
+
+	foreign := func() *Blueprint {
+		return Schema.Alter("posts", func(t *Blueprint) {
+			t.Foreign("user_id").References("id").On("users").OnUpdate("restrict")
+			t.DropForeign("posts_author_id_foreign")
+		})
+	}
+
+	schemaEqual(t, "TestSchemaAlter", foreign().ToSQL("postgres"), []string{
+		`ALTER TABLE "posts" ADD CONSTRAINT "posts_user_id_foreign" FOREIGN KEY ( "user_id" ) REFERENCES "users" ( "id" ) ON UPDATE RESTRICT`,
+		`ALTER TABLE "posts" DROP CONSTRAINT "posts_author_id_foreign"`,
+	})
+
+	schemaEqual(t, "TestSchemaAlter", foreign().ToSQL("mysql"), []string{
+		"ALTER TABLE `posts` ADD CONSTRAINT `posts_user_id_foreign` FOREIGN KEY ( `user_id` ) REFERENCES `users` ( `id` ) ON UPDATE RESTRICT",
+		"ALTER TABLE `posts` DROP FOREIGN KEY `posts_author_id_foreign`",
+	})
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expect panic in func TestSchemaAlter for sqlite3 foreign key")
+		}
+	}()
+	foreign().ToSQL("sqlite3")
+}
+
+func TestSchemaExecError(t *testing.T) {
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	err := DataBase(db, "sqlite3").ExecSchema(context.Background(), schemaUsers(), Schema.Alter("posts", func(t *Blueprint) {
+		t.DropForeign("posts_user_id_foreign")
+	}))
+	if err == nil {
+		t.Errorf("Expect error for sqlite3 drop foreign in func TestSchemaExecError")
+	}
+	if q := fake.queries(); len(q) != 0 {
+		t.Errorf("Expect no queries before unsupported operation in func TestSchemaExecError: %q", q)
+	}
+}
+
+func TestSchemaDrop(t *testing.T) {
+	schemaEqual(t, "TestSchemaDrop", Schema.Drop("users").ToSQL("postgres"), []string{`DROP TABLE "users"`})
+	schemaEqual(t, "TestSchemaDrop", Schema.DropIfExists("users").ToSQL("mysql"), []string{"DROP TABLE IF EXISTS `users`"})
+	schemaEqual(t, "TestSchemaDrop", Schema.Rename("users", "members").ToSQL("sqlite3"), []string{"ALTER TABLE `users` RENAME TO `members`"})
+}
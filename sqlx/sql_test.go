@@ -386,13 +386,11 @@ func TestSqlInsert4(t *testing.T) {
 }
 
 func TestSqlInsert5(t *testing.T) {
-	Driver("mysql")
 	expect := "INSERT IGNORE INTO `users` ( `id`, `name` ) VALUES ( ?, ? ), ( ?, ? )"
-	result := Table("users").Insert(Data{"id": 1, "name": "Jack"}).Insert(Data{"id": 2, "name": "Mike"}).OrIgnore().Sql()
+	result, _ := Table("users").Insert(Data{"id": 1, "name": "Jack"}).Insert(Data{"id": 2, "name": "Mike"}).OrIgnore().ToSQL("mysql")
 	if result != expect {
 		t.Errorf("Expect result to equal in func TestSqlInsert5.\nResult: %s\nExpect: %s", result, expect)
 	}
-	Driver("postgres")
 }
 
 func TestSqlInsert6(t *testing.T) {
@@ -402,3 +400,297 @@ func TestSqlInsert6(t *testing.T) {
 		t.Errorf("Expect result to equal in func TestSqlInsert6.\nResult: %s\nExpect: %s", result, expect)
 	}
 }
+
+func TestSqlToSQL(t *testing.T) {
+	builder := Table("users").Where("id", "=", 1).OrIgnore().Insert(Data{"id": 1, "name": "Jack"})
+
+	expect := "INSERT IGNORE INTO `users` ( `id`, `name` ) VALUES ( ?, ? )"
+	result, data := builder.ToSQL("mysql")
+	if result != expect || !DataEqual(data, []interface{}{1, "Jack"}) {
+		t.Errorf("Expect result to equal in func TestSqlToSQL.\nResult: %s %v\nExpect: %s", result, data, expect)
+	}
+
+	expect = "INSERT OR IGNORE INTO `users` ( `id`, `name` ) VALUES ( ?, ? )"
+	result, _ = builder.ToSQL("sqlite3")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlToSQL.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	expect = `INSERT INTO "users" ( "id", "name" ) VALUES ( $1, $2 ) ON CONFLICT DO NOTHING`
+	result = builder.Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlToSQL.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUpsert1(t *testing.T) {
+	expect := `INSERT INTO "users" ( "email", "name" ) VALUES ( $1, $2 ) ON CONFLICT ( "email" ) DO UPDATE SET "name" = EXCLUDED."name"`
+	result := Table("users").Upsert(Data{"email": "jack@mail.ru", "name": "Jack"}, []string{"email"}, []string{"name"}).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUpsert1.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUpsert2(t *testing.T) {
+	expect := `INSERT INTO "counters" ( "hits", "page" ) VALUES ( $1, $2 ), ( $3, $4 ) ON CONFLICT ( "page" ) DO UPDATE SET "hits" = "counters"."hits" + $5, "updated" = $6 RETURNING "id"`
+	result := Table("counters").Upsert([]Data{{"page": "/", "hits": 1}, {"page": "/news", "hits": 1}}, []string{"page"}, Data{
+		"hits":    Raw(`"counters"."hits" + ?`, 1),
+		"updated": "2018-01-01",
+	}).ReturnId().Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUpsert2.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUpsert3(t *testing.T) {
+	expect := "INSERT INTO `users` ( `email`, `name` ) VALUES ( ?, ? ) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)"
+	result, _ := Table("users").Upsert(Data{"email": "jack@mail.ru", "name": "Jack"}, []string{"email"}, []string{"name"}).ToSQL("mysql")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUpsert3.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUpsert4(t *testing.T) {
+	expect := "INSERT INTO `users` ( `email`, `name` ) VALUES ( ?, ? ) ON CONFLICT ( `email` ) DO UPDATE SET `visits` = visits + 1"
+	result, _ := Table("users").Upsert(Data{"email": "jack@mail.ru", "name": "Jack"}, []string{"email"}, Data{"visits": Raw("visits + 1")}).ToSQL("sqlite3")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUpsert4.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUpsert5(t *testing.T) {
+	expect := "INSERT INTO `users` ( `email`, `name` ) VALUES ( ?, ? ) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)"
+	builder := Table("users").Upsert(Data{"email": "jack@mail.ru", "name": "Jack"}, nil, []string{"name"})
+	result, _ := builder.ToSQL("mysql")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUpsert5.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	if _, err := DataBase(db, "postgres").Query(builder).Exec(); err == nil {
+		t.Errorf("Expect error in func TestSqlUpsert5 for postgres without conflict columns")
+	}
+	if _, err := DataBase(db, "mysql").Query(Table("users").Upsert(Data{"name": "Jack"}, nil, "name")).Exec(); err == nil {
+		t.Errorf("Expect error in func TestSqlUpsert5 for invalid update type")
+	}
+	if q := fake.queries(); len(q) != 0 {
+		t.Errorf("Expect no queries in func TestSqlUpsert5: %q", q)
+	}
+}
+
+func TestSqlReturning1(t *testing.T) {
+	expect := `UPDATE "users" SET "name" = $1 WHERE "id" = $2 RETURNING "id", "name", "updated_at"`
+	result := Table("users").Where("id", "=", 15).Update(Data{"name": "Jack"}).Returning("id", "name", "updated_at").Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlReturning1.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlReturning2(t *testing.T) {
+	expect := "DELETE FROM `users` WHERE `id` = ? RETURNING `id`, `email`"
+	result, _ := Table("users").Where("id", "=", 15).Delete().Returning("id", "email").ToSQL("sqlite3")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlReturning2.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlReturning3(t *testing.T) {
+	expect := "INSERT INTO `users` ( `name` ) VALUES ( ? )"
+	result, _ := Table("users").Insert(Data{"name": "Jack"}).Returning("id").ToSQL("mysql")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlReturning3.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlWith1(t *testing.T) {
+	expect := `WITH "active" AS ( SELECT "id", "name" FROM "users" WHERE "status" = $1 ) SELECT * FROM "active" WHERE "id" > $2`
+	active := Table("users").Select("id", "name").Where("status", "=", "active")
+	result := Table("active").With("active", active).Where("id", ">", 10).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlWith1.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlWith2(t *testing.T) {
+	expect := `WITH RECURSIVE "tree" ( "id", "parent_id" ) AS ( SELECT "id", "parent_id" FROM "categories" WHERE "id" = $1 UNION ALL SELECT "c"."id", "c"."parent_id" FROM "categories" as "c" INNER JOIN "tree" ON ( "tree"."id" = "c"."parent_id" ) ) SELECT "id" FROM "tree" LIMIT $2`
+	anchor := Table("categories").Select("id", "parent_id").Where("id", "=", 1)
+	recursive := Table("categories c").Select("c.id", "c.parent_id").Join("tree", func(j *Joiner) {
+		j.On("tree.id", "=", "c.parent_id")
+	})
+	result := Table("tree").WithRecursive("tree", []string{"id", "parent_id"}, anchor, recursive).Select("id").Limit(100).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlWith2.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlWith3(t *testing.T) {
+	expect := "WITH `old` AS ( SELECT `id` FROM `sessions` WHERE `created` < ? ) DELETE FROM `sessions` WHERE `id` IN ( SELECT `id` FROM `old` )"
+	old := Table("sessions").Select("id").Where("created", "<", "2018-01-01")
+	result, _ := Table("sessions").With("old", old).WhereIn("id", Table("old").Select("id")).Delete().ToSQL("mysql")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlWith3.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUnion1(t *testing.T) {
+	expect := `( SELECT "id", "name" FROM "users" WHERE "status" = $1 ) UNION ( SELECT "id", "name" FROM "admins" WHERE "level" > $2 ORDER BY "id" DESC LIMIT $3 ) ORDER BY "name" ASC LIMIT $4 OFFSET $5`
+	admins := Table("admins").Select("id", "name").Where("level", ">", 2).OrderBy("id", "DESC").Limit(5)
+	result := Table("users").Select("id", "name").Where("status", "=", "active").Union(admins).OrderBy("name", "ASC").Limit(10).Offset(20).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUnion1.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUnion2(t *testing.T) {
+	expect := "( ( ( SELECT `id` FROM `a` ) UNION ALL ( SELECT `id` FROM `b` ) ) INTERSECT ( SELECT `id` FROM `c` ) ) EXCEPT ( SELECT `id` FROM `d` )"
+	result, _ := Table("a").Select("id").UnionAll(Table("b").Select("id")).Intersect(Table("c").Select("id")).Except(Table("d").Select("id")).ToSQL("mysql")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUnion2.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUnion3(t *testing.T) {
+	expect := "SELECT `id` FROM `a` WHERE `x` = ? UNION SELECT `id` FROM `b` UNION SELECT * FROM ( SELECT `id` FROM `c` ORDER BY `id` DESC LIMIT ? ) ORDER BY `id` ASC LIMIT ?"
+	c := Table("c").Select("id").OrderBy("id", "DESC").Limit(1)
+	result, _ := Table("a").Select("id").Where("x", "=", 1).Union(Table("b").Select("id")).Union(c).OrderBy("id", "ASC").Limit(10).ToSQL("sqlite3")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUnion3.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlUnion4(t *testing.T) {
+	union := func() *Builder {
+		return Table("a").Select("id").Union(Table("b").Select("id")).Union(Table("c").Select("id")).Intersect(Table("d").Select("id")).Except(Table("e").Select("id"))
+	}
+
+	expect := `( ( ( SELECT "id" FROM "a" ) UNION ( SELECT "id" FROM "b" ) UNION ( SELECT "id" FROM "c" ) ) INTERSECT ( SELECT "id" FROM "d" ) ) EXCEPT ( SELECT "id" FROM "e" )`
+	result, _ := union().ToSQL("postgres")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUnion4.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	expect = "( ( ( SELECT `id` FROM `a` ) UNION ( SELECT `id` FROM `b` ) UNION ( SELECT `id` FROM `c` ) ) INTERSECT ( SELECT `id` FROM `d` ) ) EXCEPT ( SELECT `id` FROM `e` )"
+	result, _ = union().ToSQL("mysql")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUnion4.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	expect = "SELECT * FROM ( SELECT * FROM ( SELECT `id` FROM `a` UNION SELECT `id` FROM `b` UNION SELECT `id` FROM `c` ) INTERSECT SELECT `id` FROM `d` ) EXCEPT SELECT `id` FROM `e`"
+	result, _ = union().ToSQL("sqlite3")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUnion4.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlRightJoin(t *testing.T) {
+	expect := `SELECT * FROM "users" as "us" RIGHT JOIN "orders" as "ord" ON ( "us"."id" = "ord"."user_id" ) FULL OUTER JOIN "payments" as "p" ON ( "p"."order_id" = "ord"."id" ) CROSS JOIN "currencies"`
+	result := Table("users as us").RightJoin("orders as ord", func(joiner *Joiner) {
+		joiner.On("us.id", "=", "ord.user_id")
+	}).FullJoin("payments as p", func(joiner *Joiner) {
+		joiner.On("p.order_id", "=", "ord.id")
+	}).CrossJoin("currencies").Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlRightJoin.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	full := Table("users").FullJoin("orders", func(joiner *Joiner) {
+		joiner.On("orders.user_id", "=", "users.id")
+	})
+	if _, err := DataBase(db, "mysql").Query(full).Exec(); err == nil {
+		t.Errorf("Expect error for mysql full join in func TestSqlRightJoin")
+	}
+	if _, err := DataBase(db, "postgres").Query(full).Exec(); err != nil {
+		t.Errorf("Unexpected error for postgres full join in func TestSqlRightJoin: %s", err)
+	}
+	if q := fake.queries(); len(q) != 1 {
+		t.Errorf("Expect only postgres query in func TestSqlRightJoin: %q", q)
+	}
+}
+
+func TestSqlWhereInEmpty(t *testing.T) {
+	expect := `SELECT * FROM "users" INNER JOIN "roles" ON ( "roles"."id" = "users"."role_id" AND 0 = 1 ) WHERE 0 = 1 OR 1 = 1`
+	builder := Table("users").Join("roles", func(joiner *Joiner) {
+		joiner.On("roles.id", "=", "users.role_id").WhereIn("roles.name", List{})
+	}).WhereIn("id", List{}).OrWhereNotIn("id", List{})
+	if result := builder.Sql(); result != expect || len(builder.Data()) != 0 {
+		t.Errorf("Expect result to equal in func TestSqlWhereInEmpty.\nResult: %s %v\nExpect: %s", result, builder.Data(), expect)
+	}
+}
+
+func TestSqlJoinSub(t *testing.T) {
+	expect := `SELECT * FROM "users" as "us" INNER JOIN ( SELECT "user_id", MAX(created) as last FROM "orders" WHERE "status" = $1 GROUP BY "user_id" ) as "o" ON ( "o"."user_id" = "us"."id" AND ( "o"."last" > $2 OR "o"."last" IS NULL ) AND "us"."role" IN ( $3, $4 ) ) WHERE "us"."id" > $5`
+	orders := Table("orders").Select("user_id", Raw("MAX(created) as last")).Where("status", "=", "paid").GroupBy("user_id")
+	result := Table("users as us").JoinSub(orders, "o", func(joiner *Joiner) {
+		joiner.On("o.user_id", "=", "us.id").OnGroup(func(joiner *Joiner) {
+			joiner.Where("o.last", ">", "2018-01-01").OrWhereNull("o.last")
+		}).WhereIn("us.role", List{"admin", "manager"})
+	}).Where("us.id", ">", 10).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlJoinSub.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlJoinLateral(t *testing.T) {
+	expect := `SELECT * FROM "users" as "us" LEFT JOIN LATERAL ( SELECT "id" FROM "orders" WHERE "orders"."user_id" = us.id ORDER BY "id" DESC LIMIT $1 ) as "o" ON TRUE`
+	orders := Table("orders").Select("id").WhereRaw(`"orders"."user_id" = us.id`).OrderBy("id", "DESC").Limit(3)
+	result := Table("users as us").LeftJoinLateral(orders, "o", nil).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlJoinLateral.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlWindow(t *testing.T) {
+	expect := `SELECT "id", ROW_NUMBER() OVER ( PARTITION BY "user_id" ORDER BY "created" DESC ) as "num", LAG("total", 1) OVER ( ORDER BY "id" ASC ) as "prev", RANK() OVER ( PARTITION BY "country", "city" ) FROM "orders"`
+	result := Table("orders").Select(
+		"id",
+		RowNumber().Over(PartitionBy("user_id").OrderBy("created", "DESC")).As("num"),
+		Lag("total", 1).Over(NewWindow().OrderBy("id", "ASC")).As("prev"),
+		Rank().Over(PartitionBy("country", "city")),
+	).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlWindow.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlDistinct(t *testing.T) {
+	expect := "SELECT DISTINCT `city`, COUNT(DISTINCT `user_id`) as `users`, COALESCE(`nickname`, `name`) as `title` FROM `orders` GROUP BY `city`"
+	result, _ := Table("orders").Distinct().Select("city", CountDistinct("user_id").As("users"), Fn("COALESCE", "nickname", "name").As("title")).GroupBy("city").ToSQL("mysql")
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlDistinct.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlCase(t *testing.T) {
+	expect := `SELECT "id", CASE WHEN "total" > $1 THEN $2 WHEN "total" > $3 THEN $4 ELSE $5 END as "level", ( SELECT COUNT(*) FROM "items" WHERE "items"."order_id" = orders.id AND "deleted" = $6 ) as "items" FROM "orders" WHERE "status" = $7`
+	level := Case().When("total", ">", 1000, "gold").When("total", ">", 100, "silver").Else("bronze").As("level")
+	items := Table("items").Select(Fn("COUNT", Raw("*"))).WhereRaw(`"items"."order_id" = orders.id`).Where("deleted", "=", false)
+	result := Table("orders").Select("id", level).SelectSub(items, "items").Where("status", "=", "paid").Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlCase.\nResult: %s\nExpect: %s", result, expect)
+	}
+}
+
+func TestSqlClauseErrors(t *testing.T) {
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+	dbx := DataBase(db)
+
+	injected := RowNumber().Over(PartitionBy("user_id").OrderBy("id", "DESC; DROP TABLE users"))
+	if _, err := dbx.Query(Table("orders").Select("id", injected)).Exec(); err == nil {
+		t.Errorf("Expect error for window order direction in func TestSqlClauseErrors")
+	}
+	if _, err := dbx.Query(Table("orders").Select("id", Case().Else("none"))).Exec(); err == nil {
+		t.Errorf("Expect error for case without when in func TestSqlClauseErrors")
+	}
+	if _, err := dbx.Query(Table("orders").Select(RowNumber().Over(NewWindow().OrderBy("id", "desc")))).Exec(); err != nil {
+		t.Errorf("Unexpected error for lower case direction in func TestSqlClauseErrors: %s", err)
+	}
+	if q := fake.queries(); len(q) != 1 {
+		t.Errorf("Expect only valid query in func TestSqlClauseErrors: %q", q)
+	}
+}
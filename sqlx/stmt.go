@@ -0,0 +1,132 @@
+package sqlx
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// Статистика кеша подготовленных выражений
+type CacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Size      int
+}
+
+// LRU кеш подготовленных выражений, ключ - скомпилированная строка запроса
+// Вытесненное выражение закрывается, когда его освободит последний запрос
+type stmtCache struct {
+	db    *sql.DB
+	limit int
+	mutex sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+	stats CacheStats
+}
+
+type stmtEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, limit int) *stmtCache {
+	return &stmtCache{
+		db:    db,
+		limit: limit,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Подготовленное выражение из кеша или подготовленное заново
+// После использования выражение нужно вернуть через release
+func (self *stmtCache) get(ctx context.Context, query string) (*stmtEntry, error) {
+	self.mutex.Lock()
+	if elem, ok := self.items[query]; ok {
+		self.order.MoveToFront(elem)
+		self.stats.Hits++
+		entry := elem.Value.(*stmtEntry)
+		entry.refs++
+		self.mutex.Unlock()
+		return entry, nil
+	}
+	self.stats.Misses++
+	self.mutex.Unlock()
+
+	// Подготовка идет без блокировки, чтобы не задерживать другие запросы
+	stmt, err := self.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	// Пока готовили, выражение мог добавить другой запрос
+	if elem, ok := self.items[query]; ok {
+		stmt.Close()
+		self.order.MoveToFront(elem)
+		entry := elem.Value.(*stmtEntry)
+		entry.refs++
+		return entry, nil
+	}
+
+	entry := &stmtEntry{query: query, stmt: stmt, refs: 1}
+	self.items[query] = self.order.PushFront(entry)
+
+	for self.order.Len() > self.limit {
+		elem := self.order.Back()
+		self.order.Remove(elem)
+		self.evict(elem.Value.(*stmtEntry))
+		self.stats.Evictions++
+	}
+
+	return entry, nil
+}
+
+// Возвращает выражение в кеш, вытесненное закрывается последним запросом
+func (self *stmtCache) release(entry *stmtEntry) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// Убирает выражение из кеша, занятое выражение закроет release
+func (self *stmtCache) evict(entry *stmtEntry) error {
+	delete(self.items, entry.query)
+	entry.evicted = true
+	if entry.refs == 0 {
+		return entry.stmt.Close()
+	}
+	return nil
+}
+
+func (self *stmtCache) statistics() CacheStats {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	stats := self.stats
+	stats.Size = self.order.Len()
+	return stats
+}
+
+// Закрывает все выражения
+func (self *stmtCache) close() error {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	var err error
+	for elem := self.order.Front(); elem != nil; elem = elem.Next() {
+		if e := self.evict(elem.Value.(*stmtEntry)); e != nil && err == nil {
+			err = e
+		}
+	}
+	self.order.Init()
+	self.items = make(map[string]*list.Element)
+	return err
+}
@@ -0,0 +1,105 @@
+package sqlx
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestStmtCache(t *testing.T) {
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	dbx := DataBase(db).StmtCache(2)
+
+	dbx.Query(Table("users").Where("id", "=", 1).Delete()).Exec()
+	dbx.Query(Table("users").Where("id", "=", 2).Delete()).Exec()
+	dbx.Query(Table("orders").Where("id", "=", 1).Delete()).Exec()
+	dbx.Query(Table("users").Where("id", "=", 3).Delete()).Exec()
+	dbx.Query(Table("posts").Where("id", "=", 1).Delete()).Exec()
+
+	stats := dbx.CacheStats()
+	expect := CacheStats{Hits: 2, Misses: 3, Evictions: 1, Size: 2}
+	if stats != expect {
+		t.Errorf("Expect result to equal in func TestStmtCache.\nResult: %+v\nExpect: %+v", stats, expect)
+	}
+
+	if fake.prepares != 3 || fake.closed != 1 {
+		t.Errorf("Unexpected prepares and closes in func TestStmtCache: %d, %d", fake.prepares, fake.closed)
+	}
+
+	tx, err := dbx.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Query(Table("users").Where("id", "=", 4).Delete()).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := dbx.CacheStats(); stats.Hits != 3 {
+		t.Errorf("Expect cache hit inside transaction in func TestStmtCache, got: %+v", stats)
+	}
+
+	dbx.StmtCache(0)
+	if fake.closed < 3 {
+		t.Errorf("Expect all statements closed in func TestStmtCache, got: %d", fake.closed)
+	}
+	if stats := dbx.CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("Expect empty stats for disabled cache in func TestStmtCache, got: %+v", stats)
+	}
+}
+
+func TestStmtCacheConcurrent(t *testing.T) {
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	dbx := DataBase(db).StmtCache(1)
+	tables := []string{"users", "orders", "posts"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 300)
+	for i := 0; i < 300; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := dbx.Query(Table(tables[i%3]).Where("id", "=", i).Delete()).Exec(); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Unexpected error in func TestStmtCacheConcurrent: %s", err)
+	}
+
+	// Вытесненное выражение остается рабочим, пока его не освободят
+	cache := dbx.stmts
+	entry, err := cache.get(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := cache.get(context.Background(), "SELECT 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.release(other)
+	if _, err := entry.stmt.Exec(); err != nil {
+		t.Errorf("Expect evicted statement to stay open in func TestStmtCacheConcurrent: %s", err)
+	}
+	cache.release(entry)
+	if _, err := entry.stmt.Exec(); err == nil {
+		t.Errorf("Expect released statement to be closed in func TestStmtCacheConcurrent")
+	}
+
+	dbx.StmtCache(0)
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if fake.prepares != fake.closed {
+		t.Errorf("Expect all statements closed in func TestStmtCacheConcurrent: %d, %d", fake.prepares, fake.closed)
+	}
+}
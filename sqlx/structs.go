@@ -0,0 +1,139 @@
+package sqlx
+
+import (
+	"reflect"
+)
+
+// Вставка строк из структур
+//
+// Колонки берутся из тегов db или через toSnake: UserName -> user_name
+// Пропускаются поля с опцией auto, целочисленный id без опций, если он не заполнен,
+// и поля с опцией omitempty, нулевые во всех структурах.
+// Если поле omitempty заполнено хотя бы в одной структуре,
+// остальные строки получат в этой колонке нулевое значение.
+// Если переданы указатели, после Exec первичный ключ и другие колонки
+// из Returning заполняются через RETURNING. MySQL заполняет только
+// первичный ключ через LastInsertId и только при вставке одной структуры.
+//
+//	type User struct {
+//	    Id      int64  `db:"id,pk,auto"`
+//	    Name    string `db:"name"`
+//	    Comment string `db:"comment,omitempty"`
+//	}
+func (self *Builder) InsertStruct(v ...interface{}) *Builder {
+	if len(v) == 0 {
+		return self
+	}
+
+	values := make([]reflect.Value, len(v))
+	for k, s := range v {
+		values[k] = structValue(s)
+		if values[k].Type() != values[0].Type() {
+			panic("sqlx: insert struct values must be of the same type")
+		}
+	}
+	fm := structFieldMap(values[0].Type())
+
+	columns := make([]*field, 0, len(fm.fields))
+	for _, f := range fm.fields {
+		if f.nested || f.auto(values) {
+			continue
+		}
+		if f.option("omitempty") && allZero(values, f.index) {
+			continue
+		}
+		columns = append(columns, f)
+	}
+
+	data := make([]Data, len(values))
+	for k, value := range values {
+		data[k] = Data{}
+		for _, f := range columns {
+			data[k][f.column] = value.FieldByIndex(f.index).Interface()
+		}
+	}
+
+	self.Insert(data...)
+
+	if self.kind != "insert" {
+		return self
+	}
+
+	if pk := fm.primary(); pk != nil && pk.auto(values) {
+		self.primary = pk
+		for _, s := range v {
+			if ptr := reflect.ValueOf(s); ptr.Kind() == reflect.Ptr {
+				self.structs = append(self.structs, ptr.Elem())
+			} else {
+				self.structs = append(self.structs, reflect.Value{})
+			}
+		}
+		if len(self.components.Returning) == 0 {
+			self.Returning(pk.column)
+		}
+	}
+
+	return self
+}
+
+// Обновление строки по первичному ключу структуры
+// Без columns обновляются все поля, кроме первичного ключа, auto
+// и нулевых значений полей с опцией omitempty
+func (self *Builder) UpdateStruct(v interface{}, columns ...string) *Builder {
+	value := structValue(v)
+	fm := structFieldMap(value.Type())
+
+	pk := fm.primary()
+	if pk == nil {
+		panic("sqlx: update struct requires primary key field")
+	}
+
+	data := Data{}
+	if len(columns) > 0 {
+		for _, c := range columns {
+			f, ok := fm.lookup(c)
+			if !ok {
+				panic("sqlx: field for column '" + c + "' not found")
+			}
+			data[f.column] = value.FieldByIndex(f.index).Interface()
+		}
+	} else {
+		for _, f := range fm.fields {
+			if f == pk || f.nested || f.option("auto") {
+				continue
+			}
+			field := value.FieldByIndex(f.index)
+			if f.option("omitempty") && field.IsZero() {
+				continue
+			}
+			data[f.column] = field.Interface()
+		}
+	}
+
+	if self.kind != "" {
+		return self
+	}
+
+	self.Update(data)
+	self.Where(pk.column, "=", value.FieldByIndex(pk.index).Interface())
+
+	return self
+}
+
+func structValue(v interface{}) reflect.Value {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		panic("sqlx: value must be a struct or a pointer to struct")
+	}
+	return value
+}
+
+// Поле нулевое во всех структурах
+func allZero(values []reflect.Value, index []int) bool {
+	for _, v := range values {
+		if !v.FieldByIndex(index).IsZero() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package sqlx
+
+import (
+	sqldriver "database/sql/driver"
+	"testing"
+)
+
+type structsUser struct {
+	Id       int64
+	UserName string
+	Email    string       `db:"mail"`
+	Comment  string       `db:"comment,omitempty"`
+	Secret   string       `db:"-"`
+	Author   mapperAuthor `db:"author_"`
+}
+
+func TestSqlInsertStruct(t *testing.T) {
+	expect := `INSERT INTO "users" ( "mail", "user_name" ) VALUES ( $1, $2 ), ( $3, $4 ) RETURNING "id"`
+	result := Table("users").InsertStruct(
+		&structsUser{UserName: "Jack", Email: "jack@mail.ru"},
+		&structsUser{UserName: "Mike", Email: "mike@mail.ru"},
+	).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlInsertStruct.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	expect = `INSERT INTO "users" ( "comment", "mail", "user_name" ) VALUES ( $1, $2, $3 ), ( $4, $5, $6 ) RETURNING "id"`
+	builder := Table("users").InsertStruct(
+		&structsUser{UserName: "Jack", Email: "jack@mail.ru"},
+		&structsUser{UserName: "Mike", Email: "mike@mail.ru", Comment: "kept"},
+	)
+	result, data := builder.Sql(), builder.Data()
+	if result != expect || !DataEqual(data, []interface{}{"", "jack@mail.ru", "Jack", "kept", "mike@mail.ru", "Mike"}) {
+		t.Errorf("Expect result to equal in func TestSqlInsertStruct.\nResult: %s %v\nExpect: %s", result, data, expect)
+	}
+
+	expect = `INSERT INTO "users" ( "id", "mail", "user_name" ) VALUES ( $1, $2, $3 )`
+	builder = Table("users").InsertStruct(&structsUser{Id: 5, UserName: "Jack", Email: "jack@mail.ru"})
+	if result := builder.Sql(); result != expect || builder.primary != nil {
+		t.Errorf("Expect result to equal in func TestSqlInsertStruct.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	expect = "INSERT INTO `users` ( `comment`, `mail`, `user_name` ) VALUES ( ?, ?, ? )"
+	result, data = Table("users").InsertStruct(structsUser{UserName: "Jack", Email: "jack@mail.ru", Comment: "hi"}).ToSQL("mysql")
+	if result != expect || !DataEqual(data, []interface{}{"hi", "jack@mail.ru", "Jack"}) {
+		t.Errorf("Expect result to equal in func TestSqlInsertStruct.\nResult: %s %v\nExpect: %s", result, data, expect)
+	}
+}
+
+func TestSqlUpdateStruct(t *testing.T) {
+	user := structsUser{Id: 5, UserName: "Jack", Email: "jack@mail.ru"}
+
+	expect := `UPDATE "users" SET "mail" = $1, "user_name" = $2 WHERE "id" = $3`
+	result := Table("users").UpdateStruct(user).Sql()
+	if result != expect {
+		t.Errorf("Expect result to equal in func TestSqlUpdateStruct.\nResult: %s\nExpect: %s", result, expect)
+	}
+
+	expect = `UPDATE "users" SET "mail" = $1 WHERE "id" = $2`
+	builder := Table("users").UpdateStruct(&user, "mail")
+	if result := builder.Sql(); result != expect || !DataEqual(builder.Data(), []interface{}{"jack@mail.ru", int64(5)}) {
+		t.Errorf("Expect result to equal in func TestSqlUpdateStruct.\nResult: %s %v\nExpect: %s", result, builder.Data(), expect)
+	}
+}
+
+func TestExecInsertStruct(t *testing.T) {
+	db, _ := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		return fakeResponse{
+			columns:  []string{"id"},
+			rows:     [][]sqldriver.Value{{int64(10)}, {int64(11)}},
+			lastId:   20,
+			affected: 1,
+		}
+	})
+	defer db.Close()
+
+	jack := &structsUser{UserName: "Jack"}
+	mike := &structsUser{UserName: "Mike"}
+	res, err := DataBase(db).Query(Table("users").InsertStruct(jack, mike)).Exec()
+	if err != nil || jack.Id != 10 || mike.Id != 11 || res.RowsAffected() != 2 || res.LastInsertId() != 11 {
+		t.Errorf("Unexpected insert result in func TestExecInsertStruct: %d, %d, %v", jack.Id, mike.Id, err)
+	}
+
+	jack.Id = 0
+	if _, err := DataBase(db, "mysql").Query(Table("users").InsertStruct(jack)).Exec(); err != nil || jack.Id != 20 {
+		t.Errorf("Unexpected insert result in func TestExecInsertStruct: %d, %v", jack.Id, err)
+	}
+}
+
+func TestExecInsertStructReturning(t *testing.T) {
+	db, fake := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		return fakeResponse{
+			columns: []string{"id", "comment"},
+			rows:    [][]sqldriver.Value{{int64(10), "default"}, {int64(11), "default"}},
+		}
+	})
+	defer db.Close()
+
+	jack := &structsUser{UserName: "Jack"}
+	mike := &structsUser{UserName: "Mike"}
+	res, err := DataBase(db).Query(Table("users").Returning("id", "comment").InsertStruct(jack, mike)).Exec()
+	if err != nil || jack.Id != 10 || mike.Id != 11 || jack.Comment != "default" || mike.Comment != "default" || res.LastInsertId() != 11 {
+		t.Errorf("Unexpected insert result in func TestExecInsertStructReturning: %+v, %+v, %v", jack, mike, err)
+	}
+
+	expect := `INSERT INTO "users" ( "mail", "user_name" ) VALUES ( $1, $2 ), ( $3, $4 ) RETURNING "id", "comment"`
+	if q := fake.queries(); len(q) != 1 || q[0] != expect {
+		t.Errorf("Expect result to equal in func TestExecInsertStructReturning.\nResult: %q\nExpect: %s", q, expect)
+	}
+}
@@ -0,0 +1,174 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Повторы транзакции по умолчанию
+const (
+	DefaultTxAttempts = 3
+	DefaultTxBackoff  = 20 * time.Millisecond
+)
+
+// Количество попыток выполнения транзакции в DB.Transaction и начальная пауза между ними,
+// пауза удваивается с каждой попыткой. attempts = 1 отключает повторы
+func (self *DB) TxRetry(attempts int, backoff time.Duration) *DB {
+	if attempts < 1 {
+		attempts = 1
+	}
+	self.attempts = attempts
+	self.backoff = backoff
+	return self
+}
+
+// Выполнение fn в транзакции
+//
+// Транзакция фиксируется, если fn вернула nil, иначе откатывается,
+// в том числе при панике, которая затем пробрасывается дальше.
+// При конфликте сериализации и взаимной блокировке транзакция
+// выполняется заново, поэтому fn не должна иметь побочных эффектов вне базы данных.
+// Уровень изоляции и режим только для чтения задаются через opts.
+//
+//	err := db.Transaction(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, func(tx *sqlx.Tx) error {
+//	    _, err := tx.Query(sqlx.Table("users").Where("id", "=", 1).Update(sqlx.Data{"name": "Jack"})).Exec()
+//	    return err
+//	})
+func (self *DB) Transaction(ctx context.Context, opts *sql.TxOptions, fn func(*Tx) error) error {
+	attempts := self.attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, self.backoff<<uint(attempt-1)); err != nil {
+				return err
+			}
+		}
+
+		err = self.transaction(ctx, opts, fn)
+		if err == nil || !Retryable(self.Dialect(), err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (self *DB) transaction(ctx context.Context, opts *sql.TxOptions, fn func(*Tx) error) error {
+	tx, err := self.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return runTx(tx, fn)
+}
+
+// Выполнение fn в начатой транзакции с фиксацией или откатом
+func runTx(tx *Tx, fn func(*Tx) error) error {
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rerr := tx.Rollback(); rerr != nil && rerr != sql.ErrTxDone {
+			return fmt.Errorf("sqlx: %s; rollback: %s", err, rerr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Вложенная транзакция через SAVEPOINT
+// При ошибке или панике fn изменения откатываются до точки сохранения,
+// внешняя транзакция продолжается
+func (self *Tx) Transaction(ctx context.Context, fn func(*Tx) error) (err error) {
+	self.savepoints++
+	name := "sqlx_sp_" + strconv.Itoa(self.savepoints)
+	defer func() {
+		self.savepoints--
+	}()
+
+	if _, err := self.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			self.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(r)
+		}
+	}()
+
+	if err := fn(self); err != nil {
+		if _, rerr := self.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rerr != nil {
+			return fmt.Errorf("sqlx: %s; rollback to savepoint: %s", err, rerr)
+		}
+		return err
+	}
+
+	_, err = self.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// Ошибка конфликта сериализации или взаимной блокировки, после которой
+// транзакцию можно повторить
+//
+// postgres: SQLSTATE 40001, 40P01
+// mysql: 1213 (deadlock), 1205 (lock wait timeout)
+// sqlite3: database is locked
+func Retryable(dialect string, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		switch state.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+
+	message := err.Error()
+	for _, pattern := range retryableErrors[dialect] {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Фрагменты текстов ошибок для драйверов без кода SQLSTATE в интерфейсе
+var retryableErrors = map[string][]string{
+	"postgres": {"SQLSTATE 40001", "SQLSTATE 40P01", "could not serialize access", "deadlock detected"},
+	"mysql":    {"Error 1213", "Error 1205"},
+	"sqlite3":  {"database is locked", "SQLITE_BUSY"},
+}
+
+// Пауза с небольшим случайным разбросом, прерываемая контекстом
+func sleep(ctx context.Context, d time.Duration) error {
+	if d > 0 {
+		d += time.Duration(rand.Int63n(int64(d)/2 + 1))
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTransactionRetry(t *testing.T) {
+	failures := 1
+	db, fake := newFakeDB(func(query string, args []interface{}) fakeResponse {
+		if query == `UPDATE "users" SET "name" = $1` && failures > 0 {
+			failures--
+			return fakeResponse{err: errors.New("pq: could not serialize access due to concurrent update")}
+		}
+		return fakeResponse{}
+	})
+	defer db.Close()
+
+	calls := 0
+	err := DataBase(db).TxRetry(3, 0).Transaction(context.Background(), nil, func(tx *Tx) error {
+		calls++
+		_, err := tx.Query(Table("users").Update(Data{"name": "Jack"})).Exec()
+		return err
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Unexpected transaction result in func TestTransactionRetry: %d, %v", calls, err)
+	}
+
+	expect := []string{"BEGIN", `UPDATE "users" SET "name" = $1`, "ROLLBACK", "BEGIN", `UPDATE "users" SET "name" = $1`, "COMMIT"}
+	result := fake.queries()
+	if len(result) != len(expect) {
+		t.Fatalf("Expect result to equal in func TestTransactionRetry.\nResult: %q\nExpect: %q", result, expect)
+	}
+	for k := range expect {
+		if result[k] != expect[k] {
+			t.Errorf("Expect result to equal in func TestTransactionRetry.\nResult: %s\nExpect: %s", result[k], expect[k])
+		}
+	}
+
+	failed := errors.New("failed")
+	calls = 0
+	err = DataBase(db).Transaction(context.Background(), nil, func(tx *Tx) error {
+		calls++
+		return failed
+	})
+	if err != failed || calls != 1 {
+		t.Errorf("Expect not retryable error in func TestTransactionRetry: %d, %v", calls, err)
+	}
+}
+
+func TestTransactionPanic(t *testing.T) {
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expect panic to propagate in func TestTransactionPanic: %v", r)
+		}
+		if q := fake.queries(); len(q) != 2 || q[1] != "ROLLBACK" {
+			t.Errorf("Expect rollback in func TestTransactionPanic: %q", q)
+		}
+	}()
+
+	DataBase(db).Transaction(context.Background(), nil, func(tx *Tx) error {
+		panic("boom")
+	})
+}
+
+func TestTransactionSavepoint(t *testing.T) {
+	db, fake := newFakeDB(nil)
+	defer db.Close()
+
+	failed := errors.New("failed")
+	err := DataBase(db).Transaction(context.Background(), nil, func(tx *Tx) error {
+		if err := tx.Transaction(context.Background(), func(tx *Tx) error {
+			return tx.Transaction(context.Background(), func(tx *Tx) error {
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+		if err := tx.Transaction(context.Background(), func(tx *Tx) error {
+			return failed
+		}); err != failed {
+			t.Errorf("Expect savepoint error in func TestTransactionSavepoint: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"BEGIN",
+		"SAVEPOINT sqlx_sp_1",
+		"SAVEPOINT sqlx_sp_2",
+		"RELEASE SAVEPOINT sqlx_sp_2",
+		"RELEASE SAVEPOINT sqlx_sp_1",
+		"SAVEPOINT sqlx_sp_1",
+		"ROLLBACK TO SAVEPOINT sqlx_sp_1",
+		"COMMIT",
+	}
+	result := fake.queries()
+	if len(result) != len(expect) {
+		t.Fatalf("Expect result to equal in func TestTransactionSavepoint.\nResult: %q\nExpect: %q", result, expect)
+	}
+	for k := range expect {
+		if result[k] != expect[k] {
+			t.Errorf("Expect result to equal in func TestTransactionSavepoint.\nResult: %s\nExpect: %s", result[k], expect[k])
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	cases := []struct {
+		dialect string
+		err     error
+		expect  bool
+	}{
+		{"postgres", errors.New("ERROR: deadlock detected (SQLSTATE 40P01)"), true},
+		{"mysql", errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), true},
+		{"mysql", errors.New("Error 1062 (23000): Duplicate entry"), false},
+		{"sqlite3", errors.New("database is locked"), true},
+		{"postgres", nil, false},
+	}
+	for _, c := range cases {
+		if result := Retryable(c.dialect, c.err); result != c.expect {
+			t.Errorf("Expect result to equal in func TestRetryable.\nError: %v\nResult: %v\nExpect: %v", c.err, result, c.expect)
+		}
+	}
+}